@@ -0,0 +1,347 @@
+// Package api serves the lean REST API: chain checkpoints, blocks, states,
+// validators and node status as JSON, and blocks and states as SSZ when the
+// client sends "Accept: application/octet-stream". The fork choice tree is
+// also served as Graphviz DOT when the client sends "Accept: text/vnd.graphviz".
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/slasher"
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/types"
+)
+
+const (
+	sszContentType = "application/octet-stream"
+	dotContentType = "text/vnd.graphviz"
+)
+
+// syncDistanceTolerance is how many slots the head may lag the wall clock
+// before the node reports itself as syncing.
+const syncDistanceTolerance = 2
+
+// Service serves the REST API for a running node.
+type Service struct {
+	FC     *forkchoice.Store
+	Host   host.Host    // may be nil
+	Events *events.Feed // may be nil; disables /lean/v0/events
+	// Slasher may be nil; disables /lean/v0/slashings.
+	Slasher *slasher.Slasher
+
+	// CurrentSlot returns the wall-clock slot.
+	CurrentSlot func() uint64
+	// LocalENR returns the node's ENR, if discovery is running.
+	LocalENR func() string
+	// PeerScore returns a peer's gossipsub score, if known.
+	PeerScore func(peer.ID) (float64, bool)
+	// SyncState, if set, returns the sync state: "idle", "syncing" or "synced".
+	SyncState func() string
+
+	log    *slog.Logger
+	server *http.Server
+}
+
+// Handler returns the HTTP handler with all API routes.
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /lean/v0/checkpoints", s.getCheckpoints)
+	mux.HandleFunc("GET /lean/v0/blocks/{block_id}", s.getBlock)
+	mux.HandleFunc("GET /lean/v0/blocks/{block_id}/signed", s.getSignedBlock)
+	mux.HandleFunc("GET /lean/v0/states/{state_id}", s.getState)
+	mux.HandleFunc("GET /lean/v0/validators", s.getValidators)
+	mux.HandleFunc("GET /lean/v0/node/identity", s.getIdentity)
+	mux.HandleFunc("GET /lean/v0/node/peers", s.getPeers)
+	mux.HandleFunc("GET /lean/v0/node/syncing", s.getSyncing)
+	mux.HandleFunc("GET /lean/v0/events", s.getEvents)
+	mux.HandleFunc("GET /lean/v0/slashings", s.getSlashings)
+	mux.HandleFunc("GET /lean/v0/debug/forkchoice", s.getForkChoice)
+	return mux
+}
+
+// Start listens on the given port and serves the API in the background.
+func (s *Service) Start(port int) error {
+	s.log = logging.NewComponentLogger(logging.CompAPI)
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return fmt.Errorf("listen on api port %d: %w", port, err)
+	}
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("api server error", "err", err)
+		}
+	}()
+	s.log.Info("api server started", "port", port)
+	return nil
+}
+
+// Close stops the API server.
+func (s *Service) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Service) getCheckpoints(w http.ResponseWriter, r *http.Request) {
+	status := s.FC.GetStatus()
+	writeJSON(w, map[string]*checkpointJSON{
+		"head":      toCheckpointJSON(&types.Checkpoint{Root: status.Head, Slot: status.HeadSlot}),
+		"justified": toCheckpointJSON(&types.Checkpoint{Root: status.JustifiedRoot, Slot: status.JustifiedSlot}),
+		"finalized": toCheckpointJSON(&types.Checkpoint{Root: status.FinalizedRoot, Slot: status.FinalizedSlot}),
+	})
+}
+
+func (s *Service) getBlock(w http.ResponseWriter, r *http.Request) {
+	root, err := s.resolveBlockID(r.PathValue("block_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	block, ok := s.FC.GetBlock(root)
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	if wantsSSZ(r) {
+		writeSSZ(w, block)
+		return
+	}
+	writeJSON(w, toBlockJSON(block))
+}
+
+func (s *Service) getSignedBlock(w http.ResponseWriter, r *http.Request) {
+	root, err := s.resolveBlockID(r.PathValue("block_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sb, ok := s.FC.GetSignedBlock(root)
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	if wantsSSZ(r) {
+		writeSSZ(w, sb)
+		return
+	}
+	writeJSON(w, toSignedBlockJSON(sb))
+}
+
+// getState serves the post-state of a block. States are keyed by block root.
+func (s *Service) getState(w http.ResponseWriter, r *http.Request) {
+	root, err := s.resolveBlockID(r.PathValue("state_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	state, ok := s.FC.GetState(root)
+	if !ok {
+		writeError(w, http.StatusNotFound, "state not found")
+		return
+	}
+	if wantsSSZ(r) {
+		writeSSZ(w, state)
+		return
+	}
+	writeJSON(w, toStateJSON(state))
+}
+
+func (s *Service) getValidators(w http.ResponseWriter, r *http.Request) {
+	state, ok := s.FC.GetState(s.FC.GetStatus().Head)
+	if !ok {
+		writeError(w, http.StatusNotFound, "head state not found")
+		return
+	}
+	writeJSON(w, toValidatorsJSON(state.Validators))
+}
+
+func (s *Service) getIdentity(w http.ResponseWriter, r *http.Request) {
+	resp := struct {
+		PeerID       string   `json:"peer_id"`
+		ENR          string   `json:"enr"`
+		P2PAddresses []string `json:"p2p_addresses"`
+	}{P2PAddresses: []string{}}
+	if s.Host != nil {
+		resp.PeerID = s.Host.ID().String()
+		for _, addr := range s.Host.Addrs() {
+			resp.P2PAddresses = append(resp.P2PAddresses, addr.String()+"/p2p/"+resp.PeerID)
+		}
+	}
+	if s.LocalENR != nil {
+		resp.ENR = s.LocalENR()
+	}
+	writeJSON(w, resp)
+}
+
+type peerJSON struct {
+	PeerID    string   `json:"peer_id"`
+	Address   string   `json:"address"`
+	Direction string   `json:"direction"`
+	Score     *float64 `json:"gossip_score,omitempty"`
+}
+
+func (s *Service) getPeers(w http.ResponseWriter, r *http.Request) {
+	peers := []peerJSON{}
+	if s.Host != nil {
+		for _, conn := range s.Host.Network().Conns() {
+			p := peerJSON{
+				PeerID:    conn.RemotePeer().String(),
+				Address:   conn.RemoteMultiaddr().String(),
+				Direction: strings.ToLower(conn.Stat().Direction.String()),
+			}
+			if s.PeerScore != nil {
+				if score, ok := s.PeerScore(conn.RemotePeer()); ok {
+					p.Score = &score
+				}
+			}
+			peers = append(peers, p)
+		}
+	}
+	writeJSON(w, peers)
+}
+
+func (s *Service) getSyncing(w http.ResponseWriter, r *http.Request) {
+	status := s.FC.GetStatus()
+	currentSlot := status.HeadSlot
+	if s.CurrentSlot != nil {
+		currentSlot = max(s.CurrentSlot(), status.HeadSlot)
+	}
+	distance := currentSlot - status.HeadSlot
+	var state string
+	if s.SyncState != nil {
+		state = s.SyncState()
+	}
+	writeJSON(w, struct {
+		HeadSlot     uint64 `json:"head_slot"`
+		CurrentSlot  uint64 `json:"current_slot"`
+		SyncDistance uint64 `json:"sync_distance"`
+		IsSyncing    bool   `json:"is_syncing"`
+		SyncState    string `json:"sync_state,omitempty"`
+	}{
+		HeadSlot:     status.HeadSlot,
+		CurrentSlot:  currentSlot,
+		SyncDistance: distance,
+		IsSyncing:    distance > syncDistanceTolerance || state == "syncing",
+		SyncState:    state,
+	})
+}
+
+// getSlashings serves the recorded evidence of equivocating validators,
+// oldest first, with both conflicting signed messages of each offence.
+func (s *Service) getSlashings(w http.ResponseWriter, r *http.Request) {
+	if s.Slasher == nil {
+		writeError(w, http.StatusServiceUnavailable, "slasher not available")
+		return
+	}
+	evidence := s.Slasher.Evidence()
+	out := make([]*evidenceJSON, len(evidence))
+	for i, ev := range evidence {
+		out[i] = toEvidenceJSON(ev)
+	}
+	writeJSON(w, out)
+}
+
+// getForkChoice serves the fork choice block tree with vote weights, as JSON
+// or as a Graphviz DOT graph.
+func (s *Service) getForkChoice(w http.ResponseWriter, r *http.Request) {
+	tree := s.FC.GetTree()
+	if strings.Contains(r.Header.Get("Accept"), dotContentType) {
+		w.Header().Set("Content-Type", dotContentType)
+		io.WriteString(w, forkChoiceDOT(&tree))
+		return
+	}
+	writeJSON(w, toForkChoiceJSON(&tree))
+}
+
+// resolveBlockID maps a block identifier to a block root. Identifiers are
+// "head", "justified", "finalized", "genesis", a decimal slot on the
+// canonical chain, or a 0x-prefixed block root.
+func (s *Service) resolveBlockID(id string) ([32]byte, error) {
+	status := s.FC.GetStatus()
+	switch id {
+	case "head":
+		return status.Head, nil
+	case "justified":
+		return status.JustifiedRoot, nil
+	case "finalized":
+		return status.FinalizedRoot, nil
+	case "genesis":
+		id = "0"
+	}
+
+	if strings.HasPrefix(id, "0x") {
+		raw, err := hex.DecodeString(id[2:])
+		if err != nil || len(raw) != 32 {
+			return [32]byte{}, fmt.Errorf("invalid block root %q", id)
+		}
+		return [32]byte(raw), nil
+	}
+
+	slot, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("invalid block id %q", id)
+	}
+	blocks := s.FC.GetCanonicalBlocks(slot, 1)
+	if len(blocks) == 0 {
+		// An empty or unknown slot resolves to a root that is never found.
+		return [32]byte{}, nil
+	}
+	return blocks[0].Message.Block.HashTreeRoot()
+}
+
+func wantsSSZ(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), sszContentType)
+}
+
+func writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Data any `json:"data"`
+	}{data})
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{code, message})
+}
+
+type sszMarshaler interface {
+	MarshalSSZ() ([]byte, error)
+}
+
+func writeSSZ(w http.ResponseWriter, v sszMarshaler) {
+	data, err := v.MarshalSSZ()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "ssz encode failed")
+		return
+	}
+	w.Header().Set("Content-Type", sszContentType)
+	w.Write(data)
+}
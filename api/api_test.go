@@ -0,0 +1,229 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geanlabs/gean/api"
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/slasher"
+	"github.com/geanlabs/gean/chain/statetransition"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, [32]byte) {
+	t.Helper()
+	validators := []*types.Validator{{Pubkey: [52]byte{1}, Index: 0}, {Pubkey: [52]byte{2}, Index: 1}}
+	state := statetransition.GenerateGenesis(1000, validators)
+	block := &types.Block{Body: &types.BlockBody{Attestations: []*types.Attestation{}}}
+	block.StateRoot, _ = state.HashTreeRoot()
+	fc := forkchoice.NewStore(state, block, memory.New())
+
+	srv := httptest.NewServer((&api.Service{FC: fc, CurrentSlot: func() uint64 { return 5 }}).Handler())
+	t.Cleanup(srv.Close)
+	root, _ := block.HashTreeRoot()
+	return srv, root
+}
+
+func get(t *testing.T, url, accept string) (int, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, body
+}
+
+func TestGetCheckpoints(t *testing.T) {
+	srv, root := newTestServer(t)
+
+	code, body := get(t, srv.URL+"/lean/v0/checkpoints", "")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	var resp struct {
+		Data map[string]struct {
+			Root string `json:"root"`
+			Slot uint64 `json:"slot"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := fmt.Sprintf("0x%x", root)
+	for _, name := range []string{"head", "justified", "finalized"} {
+		if resp.Data[name].Root != want {
+			t.Fatalf("%s root = %s, want %s", name, resp.Data[name].Root, want)
+		}
+	}
+}
+
+func TestGetBlockByID(t *testing.T) {
+	srv, root := newTestServer(t)
+
+	for _, id := range []string{"head", "genesis", "0", fmt.Sprintf("0x%x", root)} {
+		code, body := get(t, srv.URL+"/lean/v0/blocks/"+id, "")
+		if code != http.StatusOK {
+			t.Fatalf("block %s: status = %d, want 200 (%s)", id, code, body)
+		}
+	}
+
+	code, body := get(t, srv.URL+"/lean/v0/blocks/head", "application/octet-stream")
+	if code != http.StatusOK {
+		t.Fatalf("ssz block: status = %d, want 200", code)
+	}
+	block := new(types.Block)
+	if err := block.UnmarshalSSZ(body); err != nil {
+		t.Fatalf("decode ssz block: %v", err)
+	}
+	if got, _ := block.HashTreeRoot(); got != root {
+		t.Fatalf("ssz block root = %x, want %x", got, root)
+	}
+
+	if code, _ := get(t, srv.URL+"/lean/v0/blocks/7", ""); code != http.StatusNotFound {
+		t.Fatalf("empty slot: status = %d, want 404", code)
+	}
+	if code, _ := get(t, srv.URL+"/lean/v0/blocks/bogus", ""); code != http.StatusBadRequest {
+		t.Fatalf("invalid id: status = %d, want 400", code)
+	}
+}
+
+func TestGetSyncing(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	_, body := get(t, srv.URL+"/lean/v0/node/syncing", "")
+	var resp struct {
+		Data struct {
+			SyncDistance uint64 `json:"sync_distance"`
+			IsSyncing    bool   `json:"is_syncing"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.SyncDistance != 5 || !resp.Data.IsSyncing {
+		t.Fatalf("syncing = %+v, want distance 5 and syncing", resp.Data)
+	}
+}
+
+func TestGetForkChoice(t *testing.T) {
+	srv, root := newTestServer(t)
+	want := fmt.Sprintf("0x%x", root)
+
+	code, body := get(t, srv.URL+"/lean/v0/debug/forkchoice", "")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	var resp struct {
+		Data struct {
+			Head  string `json:"head"`
+			Nodes []struct {
+				Root   string `json:"root"`
+				Weight int    `json:"weight"`
+			} `json:"nodes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.Head != want || len(resp.Data.Nodes) != 1 || resp.Data.Nodes[0].Root != want {
+		t.Fatalf("fork choice = %s, want genesis %s as the only node and head", body, want)
+	}
+
+	code, body = get(t, srv.URL+"/lean/v0/debug/forkchoice", "text/vnd.graphviz")
+	if code != http.StatusOK {
+		t.Fatalf("dot: status = %d, want 200", code)
+	}
+	if dot := string(body); !strings.HasPrefix(dot, "digraph") || !strings.Contains(dot, fmt.Sprintf("%x", root[:4])) {
+		t.Fatalf("dot = %q, want a digraph with the genesis node", dot)
+	}
+}
+
+func TestGetSlashings(t *testing.T) {
+	srv, _ := newTestServer(t)
+	if code, _ := get(t, srv.URL+"/lean/v0/slashings", ""); code != http.StatusServiceUnavailable {
+		t.Fatalf("no slasher: status = %d, want 503", code)
+	}
+
+	s := slasher.New(memory.New(), nil)
+	for _, head := range []byte{1, 2} {
+		s.CheckAttestation(&types.SignedAttestation{
+			Message: &types.Attestation{
+				ValidatorID: 1,
+				Data: &types.AttestationData{
+					Slot:   3,
+					Head:   &types.Checkpoint{Root: [32]byte{head}, Slot: 3},
+					Target: &types.Checkpoint{Slot: 3},
+					Source: &types.Checkpoint{},
+				},
+			},
+		})
+	}
+	srv = httptest.NewServer((&api.Service{Slasher: s}).Handler())
+	defer srv.Close()
+
+	code, body := get(t, srv.URL+"/lean/v0/slashings", "")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	var resp struct {
+		Data []struct {
+			Kind         string            `json:"kind"`
+			Validator    uint64            `json:"validator"`
+			Attestations []json.RawMessage `json:"attestations"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Kind != "double_vote" || resp.Data[0].Validator != 1 || len(resp.Data[0].Attestations) != 2 {
+		t.Fatalf("slashings = %s, want one double vote by validator 1 with both attestations", body)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	feed := events.NewFeed()
+	srv := httptest.NewServer((&api.Service{Events: feed}).Handler())
+	defer srv.Close()
+
+	if code, _ := get(t, srv.URL+"/lean/v0/events?topics=bogus", ""); code != http.StatusBadRequest {
+		t.Fatalf("unknown topic: status = %d, want 400", code)
+	}
+
+	resp, err := http.Get(srv.URL + "/lean/v0/events?topics=head")
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q, want text/event-stream", ct)
+	}
+
+	// Headers are flushed after subscribing, so these cannot be missed.
+	feed.Publish(events.TopicBlock, events.BlockEvent{Slot: 3})
+	feed.Publish(events.TopicHead, events.HeadEvent{Slot: 4, Root: [32]byte{0xab}})
+
+	r := bufio.NewReader(resp.Body)
+	event, _ := r.ReadString('\n')
+	data, _ := r.ReadString('\n')
+	if event != "event: head\n" {
+		t.Fatalf("event line = %q, want head", event)
+	}
+	if !strings.Contains(data, `"slot":4`) || !strings.Contains(data, `"root":"0xab`) {
+		t.Fatalf("data line = %q", data)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emicklei/dot"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/observability/logging"
+)
+
+// forkChoiceDOT renders the fork choice tree as a Graphviz digraph with an
+// edge from every block to each of its children. Blocks are labelled with
+// their slot, short root and weight; the head is filled, finalized blocks
+// are grey, and the justified block and safe target are outlined.
+func forkChoiceDOT(tree *forkchoice.Tree) string {
+	g := dot.NewGraph(dot.Directed)
+	g.Attr("rankdir", "LR")
+	g.NodeInitializer(func(n dot.Node) {
+		n.Box()
+		n.Attr("fontname", "monospace")
+	})
+
+	nodes := make(map[[32]byte]dot.Node, len(tree.Nodes))
+	for _, n := range tree.Nodes {
+		label := []string{
+			fmt.Sprintf("slot %d", n.Slot),
+			logging.ShortHash(n.Root),
+			fmt.Sprintf("weight %d", n.Weight),
+		}
+		node := g.Node(fmt.Sprintf("%x", n.Root))
+		switch {
+		case n.Root == tree.Head:
+			label = append(label, "head")
+			node.Attr("style", "filled").Attr("fillcolor", "gold")
+		case n.Slot <= tree.Finalized.Slot:
+			node.Attr("style", "filled").Attr("fillcolor", "lightgrey")
+		}
+		if n.Root == tree.Finalized.Root {
+			label = append(label, "finalized")
+		}
+		if n.Root == tree.Justified.Root {
+			label = append(label, "justified")
+			node.Attr("penwidth", "3")
+		}
+		if n.Root == tree.SafeTarget {
+			label = append(label, "safe target")
+			node.Attr("color", "blue")
+		}
+		node.Label(strings.Join(label, "\n"))
+		nodes[n.Root] = node
+
+		if parent, ok := nodes[n.ParentRoot]; ok {
+			g.Edge(parent, node)
+		}
+	}
+	return g.String()
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/types"
+)
+
+// sseKeepAlive is how often a comment is sent on an idle event stream so
+// proxies do not close it.
+const sseKeepAlive = 15 * time.Second
+
+// getEvents streams chain events as server-sent events. The topics query
+// parameter is a comma-separated list; all topics are streamed if it is empty.
+func (s *Service) getEvents(w http.ResponseWriter, r *http.Request) {
+	if s.Events == nil {
+		writeError(w, http.StatusServiceUnavailable, "event stream not available")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	var topics []string
+	if q := r.URL.Query().Get("topics"); q != "" {
+		for _, t := range strings.Split(q, ",") {
+			if !slices.Contains(events.Topics, t) {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown topic %q", t))
+				return
+			}
+			topics = append(topics, t)
+		}
+	}
+
+	sub := s.Events.Subscribe(topics...)
+	defer sub.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ":\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(toEventJSON(ev))
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Topic, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func toEventJSON(ev events.Event) any {
+	switch e := ev.Data.(type) {
+	case events.HeadEvent:
+		return checkpointJSON{Root: hexBytes(e.Root[:]), Slot: e.Slot}
+	case events.CheckpointEvent:
+		return checkpointJSON{Root: hexBytes(e.Root[:]), Slot: e.Slot}
+	case events.BlockEvent:
+		return struct {
+			Slot          uint64 `json:"slot"`
+			Root          string `json:"root"`
+			ProposerIndex uint64 `json:"proposer_index"`
+		}{e.Slot, hexBytes(e.Root[:]), e.ProposerIndex}
+	case events.AttestationEvent:
+		return struct {
+			*attestationJSON
+			FromBlock bool `json:"from_block"`
+		}{toAttestationJSON(&types.Attestation{ValidatorID: e.ValidatorID, Data: e.Data}), e.FromBlock}
+	case events.ReorgEvent:
+		return struct {
+			Slot           uint64 `json:"slot"`
+			Depth          uint64 `json:"depth"`
+			OldHead        string `json:"old_head"`
+			NewHead        string `json:"new_head"`
+			CommonAncestor string `json:"common_ancestor"`
+		}{e.Slot, e.Depth, hexBytes(e.OldHead[:]), hexBytes(e.NewHead[:]), hexBytes(e.CommonAncestor[:])}
+	case events.SlashingEvent:
+		return struct {
+			Kind      string `json:"kind"`
+			Validator uint64 `json:"validator"`
+			Slot      uint64 `json:"slot"`
+		}{e.Kind, e.Validator, e.Slot}
+	default:
+		return e
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/hex"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/types"
+)
+
+// JSON views of the consensus types. Roots, keys and signatures are 0x-prefixed hex.
+
+type checkpointJSON struct {
+	Root string `json:"root"`
+	Slot uint64 `json:"slot"`
+}
+
+type attestationDataJSON struct {
+	Slot   uint64          `json:"slot"`
+	Head   *checkpointJSON `json:"head"`
+	Target *checkpointJSON `json:"target"`
+	Source *checkpointJSON `json:"source"`
+}
+
+type attestationJSON struct {
+	ValidatorID uint64               `json:"validator_id"`
+	Data        *attestationDataJSON `json:"data"`
+}
+
+type blockJSON struct {
+	Slot          uint64 `json:"slot"`
+	ProposerIndex uint64 `json:"proposer_index"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	Body          struct {
+		Attestations []*attestationJSON `json:"attestations"`
+	} `json:"body"`
+}
+
+type signedBlockJSON struct {
+	Message struct {
+		Block               *blockJSON       `json:"block"`
+		ProposerAttestation *attestationJSON `json:"proposer_attestation"`
+	} `json:"message"`
+	Signature []string `json:"signature"`
+}
+
+type signedAttestationJSON struct {
+	Message   *attestationJSON `json:"message"`
+	Signature string           `json:"signature"`
+}
+
+type evidenceJSON struct {
+	Kind         string                   `json:"kind"`
+	Validator    uint64                   `json:"validator"`
+	Slot         uint64                   `json:"slot"`
+	Blocks       []*signedBlockJSON       `json:"blocks,omitempty"`
+	Attestations []*signedAttestationJSON `json:"attestations,omitempty"`
+}
+
+type forkChoiceNodeJSON struct {
+	Root          string `json:"root"`
+	ParentRoot    string `json:"parent_root"`
+	Slot          uint64 `json:"slot"`
+	ProposerIndex uint64 `json:"proposer_index"`
+	Weight        int    `json:"weight"`
+}
+
+type forkChoiceJSON struct {
+	Head       string                `json:"head"`
+	SafeTarget string                `json:"safe_target"`
+	Justified  *checkpointJSON       `json:"justified"`
+	Finalized  *checkpointJSON       `json:"finalized"`
+	Nodes      []*forkChoiceNodeJSON `json:"nodes"`
+}
+
+type blockHeaderJSON struct {
+	Slot          uint64 `json:"slot"`
+	ProposerIndex uint64 `json:"proposer_index"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	BodyRoot      string `json:"body_root"`
+}
+
+type validatorJSON struct {
+	Index  uint64 `json:"index"`
+	Pubkey string `json:"pubkey"`
+}
+
+type stateJSON struct {
+	Config struct {
+		GenesisTime uint64 `json:"genesis_time"`
+	} `json:"config"`
+	Slot                     uint64           `json:"slot"`
+	LatestBlockHeader        *blockHeaderJSON `json:"latest_block_header"`
+	LatestJustified          *checkpointJSON  `json:"latest_justified"`
+	LatestFinalized          *checkpointJSON  `json:"latest_finalized"`
+	HistoricalBlockHashes    []string         `json:"historical_block_hashes"`
+	JustifiedSlots           string           `json:"justified_slots"`
+	Validators               []*validatorJSON `json:"validators"`
+	JustificationsRoots      []string         `json:"justifications_roots"`
+	JustificationsValidators string           `json:"justifications_validators"`
+}
+
+func hexBytes(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func hexRoots(roots [][32]byte) []string {
+	out := make([]string, len(roots))
+	for i, r := range roots {
+		out[i] = hexBytes(r[:])
+	}
+	return out
+}
+
+func toCheckpointJSON(c *types.Checkpoint) *checkpointJSON {
+	if c == nil {
+		return nil
+	}
+	return &checkpointJSON{Root: hexBytes(c.Root[:]), Slot: c.Slot}
+}
+
+func toAttestationJSON(a *types.Attestation) *attestationJSON {
+	if a == nil || a.Data == nil {
+		return nil
+	}
+	return &attestationJSON{
+		ValidatorID: a.ValidatorID,
+		Data: &attestationDataJSON{
+			Slot:   a.Data.Slot,
+			Head:   toCheckpointJSON(a.Data.Head),
+			Target: toCheckpointJSON(a.Data.Target),
+			Source: toCheckpointJSON(a.Data.Source),
+		},
+	}
+}
+
+func toBlockJSON(b *types.Block) *blockJSON {
+	out := &blockJSON{
+		Slot:          b.Slot,
+		ProposerIndex: b.ProposerIndex,
+		ParentRoot:    hexBytes(b.ParentRoot[:]),
+		StateRoot:     hexBytes(b.StateRoot[:]),
+	}
+	out.Body.Attestations = []*attestationJSON{}
+	if b.Body != nil {
+		for _, a := range b.Body.Attestations {
+			out.Body.Attestations = append(out.Body.Attestations, toAttestationJSON(a))
+		}
+	}
+	return out
+}
+
+func toSignedBlockJSON(sb *types.SignedBlockWithAttestation) *signedBlockJSON {
+	out := &signedBlockJSON{Signature: make([]string, len(sb.Signature))}
+	out.Message.Block = toBlockJSON(sb.Message.Block)
+	out.Message.ProposerAttestation = toAttestationJSON(sb.Message.ProposerAttestation)
+	for i, sig := range sb.Signature {
+		out.Signature[i] = hexBytes(sig[:])
+	}
+	return out
+}
+
+func toEvidenceJSON(ev *storage.Evidence) *evidenceJSON {
+	out := &evidenceJSON{Kind: ev.Kind.String(), Validator: ev.Validator, Slot: ev.Slot}
+	for _, sb := range ev.Blocks {
+		if sb != nil {
+			out.Blocks = append(out.Blocks, toSignedBlockJSON(sb))
+		}
+	}
+	for _, sa := range ev.Attestations {
+		if sa != nil {
+			out.Attestations = append(out.Attestations, &signedAttestationJSON{
+				Message:   toAttestationJSON(sa.Message),
+				Signature: hexBytes(sa.Signature[:]),
+			})
+		}
+	}
+	return out
+}
+
+func toForkChoiceJSON(tree *forkchoice.Tree) *forkChoiceJSON {
+	out := &forkChoiceJSON{
+		Head:       hexBytes(tree.Head[:]),
+		SafeTarget: hexBytes(tree.SafeTarget[:]),
+		Justified:  toCheckpointJSON(&tree.Justified),
+		Finalized:  toCheckpointJSON(&tree.Finalized),
+		Nodes:      make([]*forkChoiceNodeJSON, len(tree.Nodes)),
+	}
+	for i, n := range tree.Nodes {
+		out.Nodes[i] = &forkChoiceNodeJSON{
+			Root:          hexBytes(n.Root[:]),
+			ParentRoot:    hexBytes(n.ParentRoot[:]),
+			Slot:          n.Slot,
+			ProposerIndex: n.ProposerIndex,
+			Weight:        n.Weight,
+		}
+	}
+	return out
+}
+
+func toValidatorsJSON(validators []*types.Validator) []*validatorJSON {
+	out := make([]*validatorJSON, len(validators))
+	for i, v := range validators {
+		out[i] = &validatorJSON{Index: v.Index, Pubkey: hexBytes(v.Pubkey[:])}
+	}
+	return out
+}
+
+func toStateJSON(s *types.State) *stateJSON {
+	out := &stateJSON{
+		Slot:                     s.Slot,
+		LatestJustified:          toCheckpointJSON(s.LatestJustified),
+		LatestFinalized:          toCheckpointJSON(s.LatestFinalized),
+		HistoricalBlockHashes:    hexRoots(s.HistoricalBlockHashes),
+		JustifiedSlots:           hexBytes(s.JustifiedSlots),
+		Validators:               toValidatorsJSON(s.Validators),
+		JustificationsRoots:      hexRoots(s.JustificationsRoots),
+		JustificationsValidators: hexBytes(s.JustificationsValidators),
+	}
+	if s.Config != nil {
+		out.Config.GenesisTime = s.Config.GenesisTime
+	}
+	if h := s.LatestBlockHeader; h != nil {
+		out.LatestBlockHeader = &blockHeaderJSON{
+			Slot:          h.Slot,
+			ProposerIndex: h.ProposerIndex,
+			ParentRoot:    hexBytes(h.ParentRoot[:]),
+			StateRoot:     hexBytes(h.StateRoot[:]),
+			BodyRoot:      hexBytes(h.BodyRoot[:]),
+		}
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+// Package checkpointsync loads a trusted finalized state and its anchor block
+// so a node can start from a recent checkpoint instead of genesis.
+package checkpointsync
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// API routes of the serving node (see package api).
+const (
+	FinalizedStatePath = "/lean/v0/states/finalized"
+	SignedBlockPath    = "/lean/v0/blocks/{root}/signed"
+)
+
+const (
+	sszContentType = "application/octet-stream"
+	fetchTimeout   = 60 * time.Second
+	// maxResponseSize bounds a downloaded state or block.
+	maxResponseSize = 256 << 20
+)
+
+// Anchor is a finalized state together with the block it is the post-state of.
+type Anchor struct {
+	State *types.State
+	Block *types.SignedBlockWithAttestation
+}
+
+// Root returns the root of the anchor block.
+func (a *Anchor) Root() [32]byte {
+	root, _ := a.Block.Message.Block.HashTreeRoot()
+	return root
+}
+
+// ReadFiles loads an anchor from SSZ-encoded state and signed block files.
+func ReadFiles(statePath, blockPath string) (*Anchor, error) {
+	stateData, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint state: %w", err)
+	}
+	blockData, err := os.ReadFile(blockPath)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint block: %w", err)
+	}
+	return decode(stateData, blockData)
+}
+
+// Fetch downloads the finalized state from another gean node at baseURL and
+// then the anchor block it commits to.
+func Fetch(ctx context.Context, baseURL string) (*Anchor, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	stateData, err := get(ctx, baseURL+FinalizedStatePath)
+	if err != nil {
+		return nil, fmt.Errorf("fetch finalized state: %w", err)
+	}
+	state := new(types.State)
+	if err := state.UnmarshalSSZ(stateData); err != nil {
+		return nil, fmt.Errorf("decode finalized state: %w", err)
+	}
+
+	root, err := anchorRoot(state)
+	if err != nil {
+		return nil, err
+	}
+	blockPath := strings.Replace(SignedBlockPath, "{root}", "0x"+hex.EncodeToString(root[:]), 1)
+	blockData, err := get(ctx, baseURL+blockPath)
+	if err != nil {
+		return nil, fmt.Errorf("fetch anchor block %x: %w", root, err)
+	}
+	return decode(stateData, blockData)
+}
+
+// Verify checks that the anchor block commits to the anchor state and that
+// the state belongs to the chain described by genesisTime and validators.
+func (a *Anchor) Verify(genesisTime uint64, validators []*types.Validator) error {
+	block := a.Block.Message.Block
+	stateRoot, err := a.State.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("hash state: %w", err)
+	}
+	if block.StateRoot != stateRoot {
+		return fmt.Errorf("block state root %x does not match state root %x", block.StateRoot, stateRoot)
+	}
+
+	wantRoot, err := anchorRoot(a.State)
+	if err != nil {
+		return err
+	}
+	if root := a.Root(); root != wantRoot {
+		return fmt.Errorf("block root %x does not match state latest block header %x", root, wantRoot)
+	}
+
+	if a.State.Config.GenesisTime != genesisTime {
+		return fmt.Errorf("state genesis time %d does not match config genesis time %d",
+			a.State.Config.GenesisTime, genesisTime)
+	}
+	if len(a.State.Validators) != len(validators) {
+		return fmt.Errorf("state has %d validators, config has %d", len(a.State.Validators), len(validators))
+	}
+	for i, v := range validators {
+		if *a.State.Validators[i] != *v {
+			return fmt.Errorf("validator %d does not match config", i)
+		}
+	}
+	return nil
+}
+
+// anchorRoot returns the root of the block a state is the post-state of. The
+// state's latest block header has a zero state root until the next slot is
+// processed, so it is filled in before hashing.
+func anchorRoot(state *types.State) ([32]byte, error) {
+	stateRoot, err := state.HashTreeRoot()
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("hash state: %w", err)
+	}
+	header := *state.LatestBlockHeader
+	if header.StateRoot == types.ZeroHash {
+		header.StateRoot = stateRoot
+	}
+	return header.HashTreeRoot()
+}
+
+func decode(stateData, blockData []byte) (*Anchor, error) {
+	state := new(types.State)
+	if err := state.UnmarshalSSZ(stateData); err != nil {
+		return nil, fmt.Errorf("decode checkpoint state: %w", err)
+	}
+	block := new(types.SignedBlockWithAttestation)
+	if err := block.UnmarshalSSZ(blockData); err != nil {
+		return nil, fmt.Errorf("decode checkpoint block: %w", err)
+	}
+	return &Anchor{State: state, Block: block}, nil
+}
+
+func get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", sszContentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
@@ -0,0 +1,72 @@
+package checkpointsync_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geanlabs/gean/api"
+	"github.com/geanlabs/gean/chain/checkpointsync"
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/statetransition"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func makeValidators(n int) []*types.Validator {
+	validators := make([]*types.Validator, n)
+	for i := range validators {
+		validators[i] = &types.Validator{Pubkey: [52]byte{byte(i + 1)}, Index: uint64(i)}
+	}
+	return validators
+}
+
+func serveGenesis(t *testing.T, validators []*types.Validator) *httptest.Server {
+	t.Helper()
+	state := statetransition.GenerateGenesis(1000, validators)
+	block := &types.Block{Body: &types.BlockBody{Attestations: []*types.Attestation{}}}
+	block.StateRoot, _ = state.HashTreeRoot()
+	fc := forkchoice.NewStore(state, block, memory.New())
+
+	srv := httptest.NewServer((&api.Service{FC: fc}).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchAndVerify(t *testing.T) {
+	validators := makeValidators(3)
+	srv := serveGenesis(t, validators)
+
+	anchor, err := checkpointsync.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if err := anchor.Verify(1000, validators); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if anchor.Block.Message.Block.Slot != 0 {
+		t.Fatalf("anchor slot = %d, want 0", anchor.Block.Message.Block.Slot)
+	}
+}
+
+func TestVerifyRejectsMismatches(t *testing.T) {
+	validators := makeValidators(3)
+	srv := serveGenesis(t, validators)
+
+	anchor, err := checkpointsync.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if err := anchor.Verify(2000, validators); err == nil {
+		t.Fatal("expected error for genesis time mismatch")
+	}
+	if err := anchor.Verify(1000, makeValidators(4)); err == nil {
+		t.Fatal("expected error for validator count mismatch")
+	}
+
+	anchor.Block.Message.Block.StateRoot = [32]byte{0xff}
+	if err := anchor.Verify(1000, validators); err == nil {
+		t.Fatal("expected error for state root mismatch")
+	}
+}
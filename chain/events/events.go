@@ -0,0 +1,144 @@
+// Package events is an in-process feed of chain events. Fork choice publishes
+// to it; the API and other subscribers consume from it.
+package events
+
+import (
+	"sync"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// Event topics.
+const (
+	TopicHead        = "head"
+	TopicBlock       = "block"
+	TopicAttestation = "attestation"
+	TopicJustified   = "justified"
+	TopicFinalized   = "finalized"
+	TopicReorg       = "reorg"
+	TopicSlashing    = "slashing"
+)
+
+// Topics lists every topic in publication order.
+var Topics = []string{TopicHead, TopicBlock, TopicAttestation, TopicJustified, TopicFinalized, TopicReorg, TopicSlashing}
+
+// subscriberBuffer is the number of events a slow subscriber may fall
+// behind before further events to it are dropped.
+const subscriberBuffer = 256
+
+// Event is a published event. Data holds the typed payload for its topic.
+type Event struct {
+	Topic string
+	Data  any
+}
+
+// HeadEvent is published when the fork choice head changes.
+type HeadEvent struct {
+	Slot uint64
+	Root [32]byte
+}
+
+// BlockEvent is published when a block is imported.
+type BlockEvent struct {
+	Slot          uint64
+	Root          [32]byte
+	ProposerIndex uint64
+}
+
+// AttestationEvent is published when a valid attestation is accepted.
+type AttestationEvent struct {
+	ValidatorID uint64
+	Data        *types.AttestationData
+	FromBlock   bool
+}
+
+// CheckpointEvent is published on TopicJustified and TopicFinalized when the
+// store's justified or finalized checkpoint advances.
+type CheckpointEvent struct {
+	Slot uint64
+	Root [32]byte
+}
+
+// ReorgEvent is published when the new head does not descend from the old
+// one. Depth is the number of blocks of the old chain above their common ancestor.
+type ReorgEvent struct {
+	Slot           uint64
+	Depth          uint64
+	OldHead        [32]byte
+	NewHead        [32]byte
+	CommonAncestor [32]byte
+}
+
+// SlashingEvent is published when a validator is caught equivocating. Kind
+// is "proposer_equivocation", "double_vote" or "surround_vote".
+type SlashingEvent struct {
+	Kind      string
+	Validator uint64
+	Slot      uint64
+}
+
+// Feed fans events out to subscribers. Publishing never blocks: events for a
+// subscriber whose buffer is full are dropped.
+type Feed struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives the events of the topics it subscribed to on C.
+type Subscription struct {
+	C <-chan Event
+
+	ch     chan Event
+	topics map[string]bool
+	feed   *Feed
+}
+
+// NewFeed creates an empty feed.
+func NewFeed() *Feed {
+	return &Feed{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber for the given topics, or all topics if none are given.
+func (f *Feed) Subscribe(topics ...string) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, feed: f}
+	if len(topics) > 0 {
+		sub.topics = make(map[string]bool, len(topics))
+		for _, t := range topics {
+			sub.topics[t] = true
+		}
+	}
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe removes the subscription and closes its channel.
+func (s *Subscription) Unsubscribe() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	if _, ok := s.feed.subs[s]; ok {
+		delete(s.feed.subs, s)
+		close(s.ch)
+	}
+}
+
+// Publish sends an event to every subscriber of its topic. It is safe to
+// call on a nil feed, which discards the event.
+func (f *Feed) Publish(topic string, data any) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subs {
+		if sub.topics != nil && !sub.topics[topic] {
+			continue
+		}
+		select {
+		case sub.ch <- Event{Topic: topic, Data: data}:
+		default:
+		}
+	}
+}
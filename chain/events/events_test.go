@@ -0,0 +1,54 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/geanlabs/gean/chain/events"
+)
+
+func TestSubscribeFiltersTopics(t *testing.T) {
+	feed := events.NewFeed()
+	sub := feed.Subscribe(events.TopicHead)
+	defer sub.Unsubscribe()
+
+	feed.Publish(events.TopicBlock, events.BlockEvent{Slot: 1})
+	feed.Publish(events.TopicHead, events.HeadEvent{Slot: 2})
+
+	ev := <-sub.C
+	if ev.Topic != events.TopicHead {
+		t.Fatalf("topic = %q, want %q", ev.Topic, events.TopicHead)
+	}
+	if head := ev.Data.(events.HeadEvent); head.Slot != 2 {
+		t.Fatalf("head slot = %d, want 2", head.Slot)
+	}
+	select {
+	case ev := <-sub.C:
+		t.Fatalf("unexpected event %+v", ev)
+	default:
+	}
+}
+
+func TestPublishDoesNotBlockOnSlowSubscriber(t *testing.T) {
+	feed := events.NewFeed()
+	sub := feed.Subscribe()
+
+	// Far more events than the buffer holds; the publisher must not block.
+	for i := 0; i < 10000; i++ {
+		feed.Publish(events.TopicHead, events.HeadEvent{Slot: uint64(i)})
+	}
+
+	// Unsubscribe closes the channel, so draining it terminates.
+	sub.Unsubscribe()
+	received := 0
+	for range sub.C {
+		received++
+	}
+	if received == 0 || received == 10000 {
+		t.Fatalf("received %d events, want a full buffer with the rest dropped", received)
+	}
+}
+
+func TestPublishOnNilFeed(t *testing.T) {
+	var feed *events.Feed
+	feed.Publish(events.TopicHead, events.HeadEvent{})
+}
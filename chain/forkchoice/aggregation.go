@@ -1,12 +1,13 @@
 package forkchoice
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 
+	"github.com/geanlabs/gean/chain/sigverify"
 	"github.com/geanlabs/gean/chain/statetransition"
 	"github.com/geanlabs/gean/types"
-	"github.com/geanlabs/gean/xmss/leansig"
 )
 
 // AggregateAttestations collects attestations for the same data and
@@ -69,87 +70,92 @@ func DisaggregateAttestation(agg *types.AggregatedAttestation) ([]uint64, [][typ
 
 // VerifyAggregatedAttestation disaggregates and verifies each XMSS signature.
 // Returns the count of valid signatures.
-func VerifyAggregatedAttestation(state *types.State, agg *types.AggregatedAttestation) (int, error) {
+func VerifyAggregatedAttestation(v *sigverify.Verifier, state *types.State, agg *types.AggregatedAttestation) (int, error) {
+	valid, err := verifyAggregate(v, state, agg, true)
+	return len(valid), err
+}
+
+// verifyAggregate splits agg into per-validator attestations and, if verify
+// is set, keeps only those whose signatures are valid against state's registry.
+func verifyAggregate(v *sigverify.Verifier, state *types.State, agg *types.AggregatedAttestation, verify bool) ([]*types.SignedAttestation, error) {
 	validatorIDs, sigs, err := DisaggregateAttestation(agg)
 	if err != nil {
-		return 0, fmt.Errorf("disaggregate: %w", err)
+		return nil, fmt.Errorf("disaggregate: %w", err)
 	}
-	verified := 0
 
+	var atts []*types.SignedAttestation
+	var jobs []sigverify.Job
 	for i, valID := range validatorIDs {
-		if valID >= uint64(len(state.Validators)) {
-			log.Warn("aggregated attestation: invalid validator index", "validator", valID)
-			continue
-		}
-		pubkey := state.Validators[valID].Pubkey
 		att := &types.Attestation{ValidatorID: valID, Data: agg.Data}
-		messageRoot, err := att.HashTreeRoot()
+		job, err := sigverify.AttestationJob(state, att, sigs[i])
 		if err != nil {
-			return 0, fmt.Errorf("hash attestation: %w", err)
+			log.Warn("aggregated attestation: invalid attestation", "validator", valID, "err", err)
+			continue
 		}
-		if err := leansig.Verify(pubkey[:], uint32(agg.Data.Slot), messageRoot, sigs[i][:]); err != nil {
+		atts = append(atts, &types.SignedAttestation{Message: att, Signature: sigs[i]})
+		jobs = append(jobs, job)
+	}
+	if !verify {
+		return atts, nil
+	}
+
+	var valid []*types.SignedAttestation
+	for i, err := range v.VerifyBatch(jobs) {
+		if err != nil {
 			log.Warn("aggregated attestation: signature invalid",
-				"validator", valID, "slot", agg.Data.Slot, "err", err,
+				"validator", atts[i].Message.ValidatorID, "slot", agg.Data.Slot, "err", err,
 			)
 			continue
 		}
-		verified++
+		valid = append(valid, atts[i])
 	}
-
-	return verified, nil
+	return valid, nil
 }
 
 // ProcessAggregatedAttestation validates and counts votes from an aggregate.
+// Signatures are verified without holding the store lock. If a block the
+// aggregate references is unknown, its valid votes are deferred until the
+// block is imported.
 func (c *Store) ProcessAggregatedAttestation(agg *types.AggregatedAttestation) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if c.NowFn != nil {
 		c.advanceTimeLocked(c.NowFn(), false)
 	}
+	invalid := c.validateAttestationData(agg.Data)
+	headState, ok := c.storage.GetState(c.head)
+	c.mu.Unlock()
 
-	if reason := c.validateAttestationData(agg.Data); reason != "" {
-		log.Debug("aggregated attestation rejected", "reason", reason, "slot", agg.Data.Slot)
+	if invalid != nil && !errors.Is(invalid, ErrUnknownBlock) {
+		log.Debug("aggregated attestation rejected", "reason", invalid, "slot", agg.Data.Slot)
 		return
 	}
-
-	headState, ok := c.storage.GetState(c.head)
 	if !ok {
 		return
 	}
 
-	validatorIDs, sigs, err := DisaggregateAttestation(agg)
+	valid, err := verifyAggregate(c.Verifier, headState, agg, c.shouldVerifySignatures())
 	if err != nil {
 		log.Warn("disaggregate failed", "err", err)
 		return
 	}
 
-	currentSlot := c.time / types.IntervalsPerSlot
-
-	for i, valID := range validatorIDs {
-		if valID >= uint64(len(headState.Validators)) {
-			continue
-		}
-		pubkey := headState.Validators[valID].Pubkey
-		att := &types.Attestation{ValidatorID: valID, Data: agg.Data}
-		messageRoot, err := att.HashTreeRoot()
-		if err != nil {
-			return
-		}
-		if err := leansig.Verify(pubkey[:], uint32(agg.Data.Slot), messageRoot, sigs[i][:]); err != nil {
-			continue
-		}
-		if agg.Data.Slot > currentSlot {
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		sa := &types.SignedAttestation{
-			Message: &types.Attestation{
-				ValidatorID: valID,
-				Data:        agg.Data,
-			},
-			Signature: sigs[i],
+	currentSlot := c.time / types.IntervalsPerSlot
+	if agg.Data.Slot > currentSlot {
+		return
+	}
+	if _, unknown := c.unknownBlockLocked(agg.Data); unknown {
+		// Only the votes whose signatures checked out wait for the block.
+		for _, sa := range valid {
+			c.deferLocked(sa)
 		}
+		return
+	}
+	for _, sa := range valid {
+		c.Slasher.CheckAttestation(sa)
+		valID := sa.Message.ValidatorID
 		existing, ok := c.latestNewAttestations[valID]
 		if !ok || existing.Message.Data.Slot < agg.Data.Slot {
 			c.latestNewAttestations[valID] = sa
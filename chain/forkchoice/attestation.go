@@ -1,15 +1,29 @@
 package forkchoice
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/chain/sigverify"
 	"github.com/geanlabs/gean/observability/metrics"
 	"github.com/geanlabs/gean/types"
 )
 
-// ProcessAttestation processes an attestation from the network.
+// ProcessAttestation processes an attestation from the network. The
+// signature is verified before the store lock is taken. If a block the
+// attestation references is unknown, it is deferred until the block is
+// imported.
 func (c *Store) ProcessAttestation(sa *types.SignedAttestation) {
+	if c.shouldVerifySignatures() {
+		if err := c.verifyAttestationSignature(sa); err != nil {
+			log.Debug("attestation rejected", "reason", err, "slot", sa.Message.Data.Slot, "validator", sa.Message.ValidatorID)
+			metrics.AttestationsInvalid.Inc()
+			return
+		}
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -20,6 +34,8 @@ func (c *Store) ProcessAttestation(sa *types.SignedAttestation) {
 	c.processAttestationLocked(sa, false)
 }
 
+// processAttestationLocked records a vote. Its signature must already have
+// been verified: by ProcessBlock for block attestations, by the caller otherwise.
 func (c *Store) processAttestationLocked(sa *types.SignedAttestation, isFromBlock bool) {
 	start := time.Now()
 	defer func() {
@@ -29,18 +45,14 @@ func (c *Store) processAttestationLocked(sa *types.SignedAttestation, isFromBloc
 	data := sa.Message.Data
 	validatorID := sa.Message.ValidatorID
 
-	if reason := c.validateAttestationData(data); reason != "" {
-		log.Debug("attestation rejected", "reason", reason, "slot", data.Slot, "validator", validatorID)
-		metrics.AttestationsInvalid.Inc()
-		return
-	}
-
-	// Verify signature (skip for on-chain attestations; already verified in ProcessBlock).
-	if !isFromBlock && c.shouldVerifySignatures() {
-		if err := c.verifyAttestationSignature(sa); err != nil {
-			metrics.AttestationsInvalid.Inc()
+	if err := c.validateAttestationData(data); err != nil {
+		if !isFromBlock && errors.Is(err, ErrUnknownBlock) {
+			c.deferLocked(sa)
 			return
 		}
+		log.Debug("attestation rejected", "reason", err, "slot", data.Slot, "validator", validatorID)
+		metrics.AttestationsInvalid.Inc()
+		return
 	}
 
 	if isFromBlock {
@@ -69,56 +81,73 @@ func (c *Store) processAttestationLocked(sa *types.SignedAttestation, isFromBloc
 		}
 	}
 
+	c.Slasher.CheckAttestation(sa)
 	metrics.AttestationsValid.Inc()
+	c.Events.Publish(events.TopicAttestation, events.AttestationEvent{
+		ValidatorID: validatorID,
+		Data:        data,
+		FromBlock:   isFromBlock,
+	})
 }
 
-// verifyAttestationSignature verifies the XMSS signature on the attestation.
+// verifyAttestationSignature verifies the XMSS signature on the attestation
+// against the head state's registry. It must be called without the lock held.
 func (c *Store) verifyAttestationSignature(sa *types.SignedAttestation) error {
+	c.mu.Lock()
 	headState, ok := c.storage.GetState(c.head)
+	c.mu.Unlock()
 	if !ok {
 		return fmt.Errorf("head state not found")
 	}
 
-	return c.verifyAttestationSignatureWithState(headState, sa.Message, sa.Signature)
+	job, err := sigverify.AttestationJob(headState, sa.Message, sa.Signature)
+	if err != nil {
+		return err
+	}
+	if err := c.Verifier.Verify(job); err != nil {
+		return fmt.Errorf("signature verification failed: %w", err)
+	}
+	return nil
 }
 
 // validateAttestationData performs attestation validation checks.
-// Returns an empty string if valid, or a rejection reason.
-func (c *Store) validateAttestationData(data *types.AttestationData) string {
+// Errors wrapping ErrInvalid mean the data can never be valid; other errors
+// mean it cannot be used yet, e.g. ErrUnknownBlock.
+func (c *Store) validateAttestationData(data *types.AttestationData) error {
 	// Availability check: source, target, and head blocks must exist.
 	sourceBlock, ok := c.storage.GetBlock(data.Source.Root)
 	if !ok {
-		return "source block unknown"
+		return fmt.Errorf("%w: source %x", ErrUnknownBlock, data.Source.Root)
 	}
 	targetBlock, ok := c.storage.GetBlock(data.Target.Root)
 	if !ok {
-		return "target block unknown"
+		return fmt.Errorf("%w: target %x", ErrUnknownBlock, data.Target.Root)
 	}
 	if _, ok := c.storage.GetBlock(data.Head.Root); !ok {
-		return "head block unknown"
+		return fmt.Errorf("%w: head %x", ErrUnknownBlock, data.Head.Root)
 	}
 
 	// Topology check.
 	if sourceBlock.Slot > targetBlock.Slot {
-		return "source slot > target slot"
+		return fmt.Errorf("%w: source slot > target slot", ErrInvalid)
 	}
 	if data.Source.Slot > data.Target.Slot {
-		return "source slot > target slot"
+		return fmt.Errorf("%w: source slot > target slot", ErrInvalid)
 	}
 
 	// Consistency check.
 	if sourceBlock.Slot != data.Source.Slot {
-		return "source checkpoint slot mismatch"
+		return fmt.Errorf("%w: source checkpoint slot mismatch", ErrInvalid)
 	}
 	if targetBlock.Slot != data.Target.Slot {
-		return "target checkpoint slot mismatch"
+		return fmt.Errorf("%w: target checkpoint slot mismatch", ErrInvalid)
 	}
 
 	// Time check.
 	currentSlot := c.time / types.IntervalsPerSlot
 	if data.Slot > currentSlot+1 {
-		return "attestation too far in future"
+		return fmt.Errorf("attestation too far in future")
 	}
 
-	return ""
+	return nil
 }
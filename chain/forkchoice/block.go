@@ -4,66 +4,77 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/chain/sigverify"
 	"github.com/geanlabs/gean/chain/statetransition"
 	"github.com/geanlabs/gean/observability/metrics"
 	"github.com/geanlabs/gean/types"
-	"github.com/geanlabs/gean/xmss/leansig"
 )
 
-func (c *Store) verifyAttestationSignatureWithState(state *types.State, att *types.Attestation, sig [3112]byte) error {
-	valID := att.ValidatorID
-	if valID >= uint64(len(state.Validators)) {
-		return fmt.Errorf("invalid validator index %d", valID)
+// verifyBlockSignatures checks the body and proposer attestation signatures
+// of an envelope against the parent state's validator registry.
+func (c *Store) verifyBlockSignatures(parentState *types.State, envelope *types.SignedBlockWithAttestation) error {
+	atts := envelope.Message.Block.Body.Attestations
+	if envelope.Message.ProposerAttestation != nil {
+		atts = append(atts[:len(atts):len(atts)], envelope.Message.ProposerAttestation)
 	}
-	pubkey := state.Validators[valID].Pubkey
-
-	messageRoot, err := att.HashTreeRoot()
-	if err != nil {
-		return fmt.Errorf("failed to hash attestation message: %w", err)
+	jobs := make([]sigverify.Job, len(atts))
+	for i, att := range atts {
+		job, err := sigverify.AttestationJob(parentState, att, envelope.Signature[i])
+		if err != nil {
+			return fmt.Errorf("attestation %d: %w", i, err)
+		}
+		jobs[i] = job
 	}
 
-	signingSlot := uint32(att.Data.Slot)
-
-	if err := leansig.Verify(pubkey[:], signingSlot, messageRoot, sig[:]); err != nil {
-		log.Warn("attestation signature invalid", "slot", att.Data.Slot, "validator", valID, "err", err)
-		return fmt.Errorf("signature verification failed: %w", err)
+	for i, err := range c.Verifier.VerifyBatch(jobs) {
+		if err == nil {
+			continue
+		}
+		log.Warn("attestation signature invalid", "slot", atts[i].Data.Slot, "validator", atts[i].ValidatorID, "err", err)
+		if i == len(envelope.Message.Block.Body.Attestations) {
+			return fmt.Errorf("invalid proposer attestation signature: %w", err)
+		}
+		return fmt.Errorf("invalid body attestation signature at index %d: %w", i, err)
 	}
-	log.Info("attestation signature verified (XMSS)", "slot", att.Data.Slot, "validator", valID, "sig_size", fmt.Sprintf("%d bytes", len(sig)))
+	log.Debug("block signatures verified (XMSS)", "slot", envelope.Message.Block.Slot, "signatures", len(jobs))
 	return nil
 }
 
 // ProcessBlock processes a new signed block envelope and updates chain state.
-// Attestation processing follows leanSpec on_block ordering:
+// Signatures are verified before the store lock is taken, so gossip keeps
+// flowing while a large block is checked. Attestation processing then
+// follows leanSpec on_block ordering:
 //  1. State transition on the bare block.
 //  2. Process body attestations as on-chain votes (is_from_block=true).
 //  3. Update head.
 //  4. Process proposer attestation as gossip vote (is_from_block=false).
+//
+// Once the lock is released, attestations that were deferred until this
+// block was known are replayed.
 func (c *Store) ProcessBlock(envelope *types.SignedBlockWithAttestation) error {
-	start := time.Now()
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if c.NowFn != nil {
-		c.advanceTimeLocked(c.NowFn(), false)
+	if err := c.importBlock(envelope); err != nil {
+		return err
 	}
+	blockHash, _ := envelope.Message.Block.HashTreeRoot()
+	c.replayPendingAttestations(blockHash)
+	return nil
+}
 
+func (c *Store) importBlock(envelope *types.SignedBlockWithAttestation) error {
+	start := time.Now()
 	block := envelope.Message.Block
 	blockHash, _ := block.HashTreeRoot()
 
-	if _, ok := c.storage.GetBlock(blockHash); ok {
+	c.mu.Lock()
+	_, known := c.storage.GetBlock(blockHash)
+	parentState, ok := c.storage.GetState(block.ParentRoot)
+	c.mu.Unlock()
+	if known {
 		return nil // already known
 	}
-
-	parentState, ok := c.storage.GetState(block.ParentRoot)
 	if !ok {
-		return fmt.Errorf("parent state not found for %x", block.ParentRoot)
-	}
-
-	stStart := time.Now()
-	state, err := statetransition.StateTransition(parentState, block)
-	metrics.StateTransitionTime.Observe(time.Since(stStart).Seconds())
-	if err != nil {
-		return fmt.Errorf("state_transition: %w", err)
+		return fmt.Errorf("%w: parent state not found for %x", ErrUnknownParent, block.ParentRoot)
 	}
 
 	// Validate signature list shape.
@@ -82,40 +93,55 @@ func (c *Store) ProcessBlock(envelope *types.SignedBlockWithAttestation) error {
 		}
 	}
 
-	// Step 1b: Verify signatures (skipped when skip_sig_verify build tag is set).
+	// Step 1a: Verify signatures without holding the lock (skipped when
+	// skip_sig_verify build tag is set). Validator keys come from the
+	// parent state (static validators).
 	if c.shouldVerifySignatures() {
-		// Verify Body Attestations.
-		for i, att := range block.Body.Attestations {
-			// Use parent state to get validator keys (static validators).
-			if err := c.verifyAttestationSignatureWithState(parentState, att, envelope.Signature[i]); err != nil {
-				return fmt.Errorf("invalid body attestation signature at index %d: %w", i, err)
-			}
-		}
-
-		// Verify proposer attestation signature (only when a proposer attestation is present).
-		if envelope.Message.ProposerAttestation != nil {
-			proposerSig := envelope.Signature[numBodyAtts] // Last signature
-			if err := c.verifyAttestationSignatureWithState(parentState, envelope.Message.ProposerAttestation, proposerSig); err != nil {
-				return fmt.Errorf("invalid proposer attestation signature: %w", err)
-			}
+		if err := c.verifyBlockSignatures(parentState, envelope); err != nil {
+			return err
 		}
 	}
 
-	c.storage.PutBlock(blockHash, block)
-	c.storage.PutSignedBlock(blockHash, envelope)
-	c.storage.PutState(blockHash, state)
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Update justified checkpoint from this block's post-state (monotonic).
-	if state.LatestJustified.Slot > c.latestJustified.Slot {
-		c.latestJustified = state.LatestJustified
+	if c.NowFn != nil {
+		c.advanceTimeLocked(c.NowFn(), false)
 	}
-	// Update finalized checkpoint from this block's post-state (monotonic).
-	if state.LatestFinalized.Slot > c.latestFinalized.Slot {
-		c.latestFinalized = state.LatestFinalized
+
+	// The store may have moved on while the signatures were being verified.
+	if _, ok := c.storage.GetBlock(blockHash); ok {
+		return nil // imported by another caller
+	}
+	if _, ok := c.storage.GetState(block.ParentRoot); !ok {
+		return fmt.Errorf("parent state not found for %x", block.ParentRoot) // pruned
+	}
+
+	// Step 1b: State transition on the bare block.
+	stStart := time.Now()
+	state, err := statetransition.StateTransition(parentState, block)
+	metrics.StateTransitionTime.Observe(time.Since(stStart).Seconds())
+	if err != nil {
+		return fmt.Errorf("state_transition: %w", err)
 	}
 
+	c.applyBlockLocked(blockHash, envelope, state)
+
+	metrics.ForkChoiceBlockProcessingTime.Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// applyBlockLocked adds a block, imported or produced locally, to the store
+// with its post-state and applies it as steps 2 to 4 of ProcessBlock
+// describe: its body attestations count as on-chain votes, the head is
+// updated, the store is pruned if finalization advanced, and its proposer
+// attestation counts as a gossip vote.
+func (c *Store) applyBlockLocked(blockHash [32]byte, envelope *types.SignedBlockWithAttestation, state *types.State) {
+	finalizedAdvanced := c.insertBlockLocked(blockHash, envelope, state)
+
 	// Step 2: Process body attestations as on-chain votes.
 	// Pair each body attestation with its signature from the envelope.
+	block := envelope.Message.Block
 	for i, att := range block.Body.Attestations {
 		sa := &types.SignedAttestation{
 			Message:   att,
@@ -127,15 +153,51 @@ func (c *Store) ProcessBlock(envelope *types.SignedBlockWithAttestation) error {
 	// Step 3: Update head.
 	c.updateHeadLocked()
 
+	if finalizedAdvanced {
+		c.pruneLocked()
+	}
+
 	// Step 4: Process proposer attestation as gossip vote (is_from_block=false).
 	if envelope.Message.ProposerAttestation != nil {
 		proposerSA := &types.SignedAttestation{
 			Message:   envelope.Message.ProposerAttestation,
-			Signature: envelope.Signature[numBodyAtts], // always last
+			Signature: envelope.Signature[len(block.Body.Attestations)], // always last
 		}
 		c.processAttestationLocked(proposerSA, false)
 	}
+}
 
-	metrics.ForkChoiceBlockProcessingTime.Observe(time.Since(start).Seconds())
-	return nil
+// insertBlockLocked stores a block with its post-state, adds it to the
+// proto-array, shows it to the slasher and publishes a block event. The justified and finalized checkpoints advance
+// to the post-state's, with their events; it reports whether finalization
+// advanced.
+func (c *Store) insertBlockLocked(blockHash [32]byte, envelope *types.SignedBlockWithAttestation, state *types.State) bool {
+	block := envelope.Message.Block
+	c.storage.PutBlockWithState(blockHash, envelope, state)
+	c.protoArray.insert(blockHash, block)
+	c.Slasher.CheckBlock(blockHash, envelope)
+	c.Events.Publish(events.TopicBlock, events.BlockEvent{
+		Slot:          block.Slot,
+		Root:          blockHash,
+		ProposerIndex: block.ProposerIndex,
+	})
+
+	// Update justified checkpoint from this block's post-state (monotonic).
+	if state.LatestJustified.Slot > c.latestJustified.Slot {
+		c.latestJustified = state.LatestJustified
+		c.Events.Publish(events.TopicJustified, events.CheckpointEvent{
+			Slot: c.latestJustified.Slot,
+			Root: c.latestJustified.Root,
+		})
+	}
+	// Update finalized checkpoint from this block's post-state (monotonic).
+	if state.LatestFinalized.Slot > c.latestFinalized.Slot {
+		c.latestFinalized = state.LatestFinalized
+		c.Events.Publish(events.TopicFinalized, events.CheckpointEvent{
+			Slot: c.latestFinalized.Slot,
+			Root: c.latestFinalized.Root,
+		})
+		return true
+	}
+	return false
 }
@@ -0,0 +1,68 @@
+package forkchoice
+
+import (
+	"testing"
+
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func TestUpdateHeadPublishesReorg(t *testing.T) {
+	db := memory.New()
+	put := func(root, parent byte, slot uint64) [32]byte {
+		r := [32]byte{root}
+		db.PutBlock(r, &types.Block{Slot: slot, ParentRoot: [32]byte{parent}})
+		return r
+	}
+
+	// genesis(1) <- a(2) <- b(3)
+	//           \<- c(4)
+	genesis := put(1, 0, 0)
+	put(2, 1, 1)
+	b := put(3, 2, 2)
+	c := put(4, 1, 1)
+
+	vote := func(head [32]byte) *types.SignedAttestation {
+		return &types.SignedAttestation{Message: &types.Attestation{Data: &types.AttestationData{
+			Head: &types.Checkpoint{Root: head},
+		}}}
+	}
+
+	feed := events.NewFeed()
+	sub := feed.Subscribe(events.TopicHead, events.TopicReorg)
+	defer sub.Unsubscribe()
+
+	anchor := &types.Checkpoint{Root: genesis}
+	store := &Store{
+		head:                    genesis,
+		latestJustified:         anchor,
+		latestFinalized:         anchor,
+		storage:                 db,
+		latestKnownAttestations: map[uint64]*types.SignedAttestation{0: vote(b)},
+		latestNewAttestations:   map[uint64]*types.SignedAttestation{},
+		protoArray:              newProtoArray(db.GetAllBlocks()),
+		knownVotes:              newVoteTracker(),
+		newVotes:                newVoteTracker(),
+		Events:                  feed,
+	}
+
+	store.updateHeadLocked()
+	if ev := <-sub.C; ev.Topic != events.TopicHead || ev.Data.(events.HeadEvent).Root != b {
+		t.Fatalf("first event = %+v, want head %x", ev, b[0])
+	}
+
+	store.latestKnownAttestations = map[uint64]*types.SignedAttestation{0: vote(c), 1: vote(c)}
+	store.updateHeadLocked()
+	if ev := <-sub.C; ev.Topic != events.TopicHead || ev.Data.(events.HeadEvent).Root != c {
+		t.Fatalf("second event = %+v, want head %x", ev, c[0])
+	}
+	ev := <-sub.C
+	reorg, ok := ev.Data.(events.ReorgEvent)
+	if !ok {
+		t.Fatalf("third event = %+v, want reorg", ev)
+	}
+	if reorg.Depth != 2 || reorg.OldHead != b || reorg.NewHead != c || reorg.CommonAncestor != genesis {
+		t.Fatalf("reorg = %+v, want depth 2 from b to c via genesis", reorg)
+	}
+}
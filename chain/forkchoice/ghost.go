@@ -6,6 +6,9 @@ import (
 )
 
 // GetForkChoiceHead uses LMD GHOST to find the head block from a given root.
+//
+// It recomputes every weight from scratch. The store itself uses the
+// incremental protoArray; this remains as the reference implementation.
 func GetForkChoiceHead(
 	store storage.Store,
 	root [32]byte,
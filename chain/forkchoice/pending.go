@@ -0,0 +1,146 @@
+package forkchoice
+
+import (
+	"slices"
+
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+const (
+	// maxPendingAttestationsPerRoot bounds the attestations deferred on any
+	// one unknown block.
+	maxPendingAttestationsPerRoot = 64
+	// maxPendingAttestationRoots bounds the unknown blocks attestations may
+	// be deferred on.
+	maxPendingAttestationRoots = 64
+	// pendingAttestationExpirySlots is how many slots past its own slot an
+	// attestation may wait for its blocks.
+	pendingAttestationExpirySlots = 4
+)
+
+// deferLocked queues sa on the first of its blocks that is unknown and asks
+// FetchBlock for that block. sa's signature must already have been
+// verified, so a peer cannot fill the queue with votes it made up. It
+// returns false, queuing nothing, if all its blocks are known.
+func (c *Store) deferLocked(sa *types.SignedAttestation) bool {
+	root, ok := c.unknownBlockLocked(sa.Message.Data)
+	if !ok {
+		return false
+	}
+	if c.queueLocked(root, sa) {
+		metrics.AttestationsDeferred.Inc()
+		log.Debug("attestation deferred",
+			"slot", sa.Message.Data.Slot,
+			"validator", sa.Message.ValidatorID,
+			"block_root", logging.ShortHash(root),
+		)
+	}
+	return true
+}
+
+// queueLocked adds sa to the attestations waiting on root. Each validator
+// has at most one deferred vote, the one with the latest slot, so a single
+// signer cannot use up the bounds on its own. It returns false if sa was
+// dropped.
+func (c *Store) queueLocked(root [32]byte, sa *types.SignedAttestation) bool {
+	validatorID := sa.Message.ValidatorID
+	if prev, ok := c.pendingValidators[validatorID]; ok {
+		if !c.unqueueLocked(prev, validatorID, sa.Message.Data.Slot) {
+			return false
+		}
+	}
+	queued, ok := c.pendingAttestations[root]
+	if len(queued) >= maxPendingAttestationsPerRoot || (!ok && len(c.pendingAttestations) >= maxPendingAttestationRoots) {
+		return false
+	}
+	if c.pendingAttestations == nil {
+		c.pendingAttestations = make(map[[32]byte][]*types.SignedAttestation)
+		c.pendingValidators = make(map[uint64][32]byte)
+	}
+	c.pendingAttestations[root] = append(queued, sa)
+	c.pendingValidators[validatorID] = root
+	if !ok && c.FetchBlock != nil {
+		go c.FetchBlock(root)
+	}
+	return true
+}
+
+// unqueueLocked removes the vote of validatorID waiting on root if it is
+// older than slot. It returns false, removing nothing, if it is not.
+func (c *Store) unqueueLocked(root [32]byte, validatorID, slot uint64) bool {
+	queued := c.pendingAttestations[root]
+	for i, sa := range queued {
+		if sa.Message.ValidatorID != validatorID {
+			continue
+		}
+		if sa.Message.Data.Slot >= slot {
+			return false
+		}
+		queued = slices.Delete(queued, i, i+1)
+		break
+	}
+	if len(queued) == 0 {
+		delete(c.pendingAttestations, root)
+	} else {
+		c.pendingAttestations[root] = queued
+	}
+	delete(c.pendingValidators, validatorID)
+	return true
+}
+
+// unknownBlockLocked returns the first of the head, target and source roots
+// of data that is not a known block.
+func (c *Store) unknownBlockLocked(data *types.AttestationData) ([32]byte, bool) {
+	for _, cp := range []*types.Checkpoint{data.Head, data.Target, data.Source} {
+		if _, ok := c.storage.GetBlock(cp.Root); !ok {
+			return cp.Root, true
+		}
+	}
+	return [32]byte{}, false
+}
+
+// replayPendingAttestations processes the attestations deferred on root,
+// which has just been imported. Those still waiting on another block are
+// queued on that block instead.
+func (c *Store) replayPendingAttestations(root [32]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	queued := c.pendingAttestations[root]
+	delete(c.pendingAttestations, root)
+	ready := 0
+	for _, sa := range queued {
+		delete(c.pendingValidators, sa.Message.ValidatorID)
+		if next, ok := c.unknownBlockLocked(sa.Message.Data); ok {
+			c.queueLocked(next, sa)
+			continue
+		}
+		c.processAttestationLocked(sa, false)
+		ready++
+	}
+	metrics.AttestationsResolved.Add(float64(ready))
+}
+
+// expirePendingAttestationsLocked drops deferred attestations more than
+// pendingAttestationExpirySlots behind the current slot.
+func (c *Store) expirePendingAttestationsLocked() {
+	currentSlot := c.time / types.IntervalsPerSlot
+	for root, queued := range c.pendingAttestations {
+		kept := queued[:0]
+		for _, sa := range queued {
+			if sa.Message.Data.Slot+pendingAttestationExpirySlots >= currentSlot {
+				kept = append(kept, sa)
+			} else {
+				delete(c.pendingValidators, sa.Message.ValidatorID)
+			}
+		}
+		clear(queued[len(kept):])
+		metrics.AttestationsExpired.Add(float64(len(queued) - len(kept)))
+		if len(kept) == 0 {
+			delete(c.pendingAttestations, root)
+		} else {
+			c.pendingAttestations[root] = kept
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package forkchoice_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func TestDeferredAttestationReplaysWhenBlockArrives(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	b1, err := producer.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	sa, err := producer.ProduceAttestation(1, 0, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceAttestation: %v", err)
+	}
+	root, _ := b1.Message.Block.HashTreeRoot()
+	if sa.Message.Data.Head.Root != root {
+		t.Fatalf("attestation head = %x, want %x", sa.Message.Data.Head.Root, root)
+	}
+
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.NowFn = func() uint64 { return 1000 + types.SecondsPerSlot }
+	fetched := make(chan [32]byte, 1)
+	fc.FetchBlock = func(root [32]byte) { fetched <- root }
+
+	if err := fc.ValidateGossipAttestation(sa); !errors.Is(err, forkchoice.ErrUnknownBlock) {
+		t.Fatalf("err = %v, want ErrUnknownBlock", err)
+	}
+	fc.ProcessAttestation(sa)
+	if got := <-fetched; got != root {
+		t.Fatalf("FetchBlock root = %x, want %x", got, root)
+	}
+	if _, ok := fc.GetNewAttestation(0); ok {
+		t.Fatal("deferred attestation recorded before its block")
+	}
+
+	if err := fc.ProcessBlock(b1); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	got, ok := fc.GetNewAttestation(0)
+	if !ok || got.Message.Data.Head.Root != root {
+		t.Fatal("deferred attestation not replayed after its block was imported")
+	}
+}
+
+func TestDeferredAttestationExpires(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	b1, err := producer.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	sa, err := producer.ProduceAttestation(1, 0, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceAttestation: %v", err)
+	}
+
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.AdvanceTime(1000+types.SecondsPerSlot, false)
+	fc.ProcessAttestation(sa)
+	fc.AdvanceTime(1000+10*types.SecondsPerSlot, false)
+
+	if err := fc.ProcessBlock(b1); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	if _, ok := fc.GetNewAttestation(0); ok {
+		t.Fatal("expired attestation replayed")
+	}
+}
+
+func TestDeferredAttestationsBoundedPerValidator(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	b1, err := producer.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	honest, err := producer.ProduceAttestation(1, 0, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceAttestation: %v", err)
+	}
+	root, _ := b1.Message.Block.HashTreeRoot()
+
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.NowFn = func() uint64 { return 1000 + types.SecondsPerSlot }
+	fetched := make(chan [32]byte, 256)
+	fc.FetchBlock = func(root [32]byte) { fetched <- root }
+
+	// One validator votes for far more unknown blocks than can be deferred.
+	for i := range 200 {
+		data := *honest.Message.Data
+		data.Head = &types.Checkpoint{Root: [32]byte{0xff, byte(i), byte(i >> 8)}, Slot: 1}
+		fc.ProcessAttestation(&types.SignedAttestation{
+			Message:   &types.Attestation{ValidatorID: 2, Data: &data},
+			Signature: honest.Signature,
+		})
+	}
+	fc.ProcessAttestation(honest)
+
+	seen := map[[32]byte]bool{}
+	for len(seen) < 2 {
+		seen[<-fetched] = true
+	}
+	if !seen[root] {
+		t.Fatal("honest attestation's block was not fetched")
+	}
+	select {
+	case r := <-fetched:
+		t.Fatalf("unexpected fetch of %x; junk votes were not bounded", r)
+	default:
+	}
+
+	if err := fc.ProcessBlock(b1); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	if got, ok := fc.GetNewAttestation(0); !ok || got.Message.Data.Head.Root != root {
+		t.Fatal("honest attestation not replayed after its block was imported")
+	}
+}
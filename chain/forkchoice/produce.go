@@ -12,6 +12,13 @@ type Signer interface {
 	Sign(signingSlot uint32, message [32]byte) ([]byte, error)
 }
 
+// SigningGuard records local signatures and refuses ones that would be
+// slashable. Each check is made, and recorded, before the signer is called.
+type SigningGuard interface {
+	CheckBlock(validator, slot uint64) error
+	CheckAttestation(validator uint64, data *types.AttestationData) error
+}
+
 // GetProposalHead returns the head for block proposal at the given slot.
 func (c *Store) GetProposalHead(slot uint64) [32]byte {
 	c.mu.Lock()
@@ -183,6 +190,15 @@ func (c *Store) ProduceBlock(slot, validatorIndex uint64, signer Signer) (*types
 		Signature: sigs,
 	}
 
+	if c.SigningGuard != nil {
+		if err := c.SigningGuard.CheckBlock(validatorIndex, slot); err != nil {
+			return nil, fmt.Errorf("slashing protection: %w", err)
+		}
+		if err := c.SigningGuard.CheckAttestation(validatorIndex, proposerAtt.Data); err != nil {
+			return nil, fmt.Errorf("slashing protection: %w", err)
+		}
+	}
+
 	// Sign proposer attestation message (validator_id + data).
 	msgRoot, err := proposerAtt.HashTreeRoot()
 	if err != nil {
@@ -195,9 +211,9 @@ func (c *Store) ProduceBlock(slot, validatorIndex uint64, signer Signer) (*types
 	}
 	copy(envelope.Signature[len(collectedSigned)][:], sig)
 
-	c.storage.PutBlock(blockHash, finalBlock)
-	c.storage.PutSignedBlock(blockHash, envelope)
-	c.storage.PutState(blockHash, finalState)
+	// The block is known from here on, so importing it back from gossip is
+	// a no-op; apply it here as an import would.
+	c.applyBlockLocked(blockHash, envelope, finalState)
 
 	return envelope, nil
 }
@@ -237,6 +253,12 @@ func (c *Store) ProduceAttestation(slot, validatorIndex uint64, signer Signer) (
 		Data:        data,
 	}
 
+	if c.SigningGuard != nil {
+		if err := c.SigningGuard.CheckAttestation(validatorIndex, data); err != nil {
+			return nil, fmt.Errorf("slashing protection: %w", err)
+		}
+	}
+
 	// Sign the attestation message root (validator_id + data).
 	messageRoot, err := att.HashTreeRoot()
 	if err != nil {
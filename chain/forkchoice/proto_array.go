@@ -0,0 +1,238 @@
+package forkchoice
+
+import (
+	"sort"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// protoArray is an incremental view of the block tree for LMD GHOST.
+//
+// Nodes are stored in insertion order and a block is always inserted after
+// its parent, so walking the slice backwards visits children before parents.
+// That lets vote deltas be pushed up to every ancestor in a single pass
+// instead of walking each vote's ancestor chain.
+type protoArray struct {
+	nodes   []*protoNode
+	indices map[[32]byte]int
+}
+
+type protoNode struct {
+	root       [32]byte
+	parentRoot [32]byte
+	slot       uint64
+	proposer   uint64
+	parent     int // -1 if the parent is not in the array
+	children   []int
+}
+
+// newProtoArray builds a proto-array from a set of blocks.
+func newProtoArray(blocks map[[32]byte]*types.Block) *protoArray {
+	roots := make([][32]byte, 0, len(blocks))
+	for root := range blocks {
+		roots = append(roots, root)
+	}
+	// A child always has a higher slot than its parent.
+	sort.Slice(roots, func(i, j int) bool {
+		return blocks[roots[i]].Slot < blocks[roots[j]].Slot
+	})
+
+	p := &protoArray{indices: make(map[[32]byte]int, len(blocks))}
+	for _, root := range roots {
+		p.insert(root, blocks[root])
+	}
+	return p
+}
+
+// insert adds a block to the array. Its parent, if known, must already be present.
+func (p *protoArray) insert(root [32]byte, block *types.Block) {
+	if _, ok := p.indices[root]; ok {
+		return
+	}
+	idx := len(p.nodes)
+	parent, ok := p.indices[block.ParentRoot]
+	if !ok {
+		parent = -1
+	}
+	p.nodes = append(p.nodes, &protoNode{
+		root:       root,
+		parentRoot: block.ParentRoot,
+		slot:       block.Slot,
+		proposer:   block.ProposerIndex,
+		parent:     parent,
+	})
+	p.indices[root] = idx
+	if parent >= 0 {
+		p.nodes[parent].children = append(p.nodes[parent].children, idx)
+	}
+}
+
+// findHead walks down from root choosing the heaviest child whose weight is
+// at least minScore. Ties are broken by highest slot, then largest hash.
+// It returns the same head as GetForkChoiceHead for the same votes.
+func (p *protoArray) findHead(root [32]byte, votes *voteTracker, minScore int) [32]byte {
+	// Start at earliest block if root is zero hash.
+	if root == types.ZeroHash && len(p.nodes) > 0 {
+		earliest := p.nodes[0]
+		for _, n := range p.nodes[1:] {
+			if n.slot < earliest.slot {
+				earliest = n
+			}
+		}
+		root = earliest.root
+	}
+
+	current, ok := p.indices[root]
+	if !ok {
+		return root
+	}
+	for {
+		best := -1
+		for _, c := range p.nodes[current].children {
+			w := votes.weight(c)
+			if w < minScore {
+				continue
+			}
+			if best < 0 {
+				best = c
+				continue
+			}
+			bw := votes.weight(best)
+			cn, bn := p.nodes[c], p.nodes[best]
+			if w > bw || (w == bw && cn.slot > bn.slot) || (w == bw && cn.slot == bn.slot && hashGreater(cn.root, bn.root)) {
+				best = c
+			}
+		}
+		if best < 0 {
+			return p.nodes[current].root
+		}
+		current = best
+	}
+}
+
+// voteTracker keeps the subtree vote weights of one set of latest
+// attestations in sync with a protoArray.
+type voteTracker struct {
+	votes   map[uint64]int // validator -> node index its vote is applied to
+	weights []int          // node index -> votes for the node and its descendants
+}
+
+func newVoteTracker() *voteTracker {
+	return &voteTracker{votes: make(map[uint64]int)}
+}
+
+func (v *voteTracker) weight(idx int) int {
+	if idx >= len(v.weights) {
+		return 0
+	}
+	return v.weights[idx]
+}
+
+// remap moves the applied votes and weights to the node indices of a pruned
+// array, as returned by protoArray.prune. Votes for dropped nodes are
+// forgotten; they only weighed on dropped nodes.
+func (v *voteTracker) remap(remap []int) {
+	for id, idx := range v.votes {
+		if remap[idx] < 0 {
+			delete(v.votes, id)
+		} else {
+			v.votes[id] = remap[idx]
+		}
+	}
+	weights := v.weights[:0]
+	for idx, w := range v.weights {
+		if remap[idx] >= 0 {
+			weights = append(weights, w)
+		}
+	}
+	v.weights = weights
+}
+
+// update applies the difference between the votes already applied and the
+// given latest attestations. Votes for blocks not in the array are ignored
+// until the block is inserted.
+func (v *voteTracker) update(p *protoArray, latest map[uint64]*types.SignedAttestation) {
+	var deltas []int
+	add := func(idx, d int) {
+		if deltas == nil {
+			deltas = make([]int, len(p.nodes))
+		}
+		deltas[idx] += d
+	}
+
+	for id, idx := range v.votes {
+		if _, ok := latest[id]; !ok {
+			add(idx, -1)
+			delete(v.votes, id)
+		}
+	}
+	for id, sa := range latest {
+		idx, ok := p.indices[sa.Message.Data.Head.Root]
+		prev, applied := v.votes[id]
+		if applied && ok && prev == idx {
+			continue
+		}
+		if applied {
+			add(prev, -1)
+			delete(v.votes, id)
+		}
+		if ok {
+			add(idx, 1)
+			v.votes[id] = idx
+		}
+	}
+	if deltas == nil {
+		return
+	}
+
+	for len(v.weights) < len(p.nodes) {
+		v.weights = append(v.weights, 0)
+	}
+	for i := len(p.nodes) - 1; i >= 0; i-- {
+		if deltas[i] == 0 {
+			continue
+		}
+		v.weights[i] += deltas[i]
+		if parent := p.nodes[i].parent; parent >= 0 {
+			deltas[parent] += deltas[i]
+		}
+	}
+}
+
+// prune drops every node other than root and its descendants. It returns,
+// for each old node index, the node's new index or -1 if it was dropped,
+// or nil if root is not in the array.
+func (p *protoArray) prune(root [32]byte) []int {
+	start, ok := p.indices[root]
+	if !ok {
+		return nil
+	}
+	remap := make([]int, len(p.nodes))
+	nodes := make([]*protoNode, 0, len(p.nodes)-start)
+	indices := make(map[[32]byte]int, len(p.nodes)-start)
+	for i, n := range p.nodes {
+		remap[i] = -1
+		// Parents precede children, so a kept parent is already remapped.
+		if i != start && (i < start || n.parent < 0 || remap[n.parent] < 0) {
+			continue
+		}
+		parent := -1
+		if i != start {
+			parent = remap[n.parent]
+		}
+		remap[i] = len(nodes)
+		indices[n.root] = len(nodes)
+		nodes = append(nodes, &protoNode{
+			root:       n.root,
+			parentRoot: n.parentRoot,
+			slot:       n.slot,
+			proposer:   n.proposer,
+			parent:     parent,
+		})
+		if parent >= 0 {
+			nodes[parent].children = append(nodes[parent].children, remap[i])
+		}
+	}
+	p.nodes, p.indices = nodes, indices
+	return remap
+}
@@ -0,0 +1,132 @@
+package forkchoice
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+// TestProtoArrayMatchesGetForkChoiceHead checks the incremental proto-array
+// against the reference LMD GHOST implementation on random block trees while
+// votes move, appear and disappear between updates.
+func TestProtoArrayMatchesGetForkChoiceHead(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 50; round++ {
+		db := memory.New()
+		var roots [][32]byte
+		p := newProtoArray(nil)
+
+		// Random tree. Forks are common and slots are skipped, so ties on
+		// weight and on slot both occur.
+		for i := 0; i < 40; i++ {
+			var root [32]byte
+			rng.Read(root[:])
+			block := &types.Block{Slot: 0}
+			if i > 0 {
+				parent := roots[rng.Intn(len(roots))]
+				pb, _ := db.GetBlock(parent)
+				block = &types.Block{Slot: pb.Slot + 1 + uint64(rng.Intn(2)), ParentRoot: parent}
+			}
+			db.PutBlock(root, block)
+			p.insert(root, block)
+			roots = append(roots, root)
+		}
+
+		votes := newVoteTracker()
+		latest := make(map[uint64]*types.SignedAttestation)
+		for step := 0; step < 20; step++ {
+			for v := uint64(0); v < 12; v++ {
+				switch rng.Intn(4) {
+				case 0:
+					delete(latest, v)
+				case 1, 2:
+					latest[v] = &types.SignedAttestation{Message: &types.Attestation{
+						ValidatorID: v,
+						Data: &types.AttestationData{
+							Head: &types.Checkpoint{Root: roots[rng.Intn(len(roots))]},
+						},
+					}}
+				}
+			}
+			votes.update(p, latest)
+
+			start := roots[rng.Intn(len(roots))]
+			for _, minScore := range []int{0, 3, 8} {
+				want := GetForkChoiceHead(db, start, latest, minScore)
+				got := p.findHead(start, votes, minScore)
+				if got != want {
+					t.Fatalf("round %d step %d minScore %d: head = %x, want %x", round, step, minScore, got[:4], want[:4])
+				}
+			}
+		}
+	}
+}
+
+// TestProtoArrayPruneKeepsSubtreeWeights checks that pruning to a subtree
+// keeps exactly its nodes and that the remapped weights match weights
+// computed from scratch on the pruned array.
+func TestProtoArrayPruneKeepsSubtreeWeights(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for round := 0; round < 50; round++ {
+		var roots [][32]byte
+		parents := make(map[[32]byte][32]byte)
+		p := newProtoArray(nil)
+		for i := 0; i < 40; i++ {
+			var root [32]byte
+			rng.Read(root[:])
+			block := &types.Block{}
+			if i > 0 {
+				parent := roots[rng.Intn(len(roots))]
+				block = &types.Block{Slot: p.nodes[p.indices[parent]].slot + 1, ParentRoot: parent}
+				parents[root] = parent
+			}
+			p.insert(root, block)
+			roots = append(roots, root)
+		}
+
+		latest := make(map[uint64]*types.SignedAttestation)
+		for v := uint64(0); v < 12; v++ {
+			latest[v] = &types.SignedAttestation{Message: &types.Attestation{
+				ValidatorID: v,
+				Data:        &types.AttestationData{Head: &types.Checkpoint{Root: roots[rng.Intn(len(roots))]}},
+			}}
+		}
+		votes := newVoteTracker()
+		votes.update(p, latest)
+
+		root := roots[rng.Intn(len(roots))]
+		inSubtree := func(r [32]byte) bool {
+			for ; ; r = parents[r] {
+				if r == root {
+					return true
+				}
+				if _, ok := parents[r]; !ok {
+					return false
+				}
+			}
+		}
+		votes.remap(p.prune(root))
+
+		for _, r := range roots {
+			if _, kept := p.indices[r]; kept != inSubtree(r) {
+				t.Fatalf("round %d: node %x kept = %v, want %v", round, r[:4], kept, !kept)
+			}
+		}
+		for v, sa := range latest {
+			if !inSubtree(sa.Message.Data.Head.Root) {
+				delete(latest, v)
+			}
+		}
+		fresh := newVoteTracker()
+		fresh.update(p, latest)
+		for i := range p.nodes {
+			if got, want := votes.weight(i), fresh.weight(i); got != want {
+				t.Fatalf("round %d: node %d weight = %d, want %d", round, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package forkchoice
+
+import (
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+// pruneLocked drops everything that can no longer become canonical once the
+// finalized checkpoint has advanced:
+//   - blocks that neither descend from the finalized block nor are its ancestors,
+//   - states of every block other than the finalized block and its descendants,
+//   - votes whose head block was pruned or sits below the finalized slot,
+//   - the slasher's record of blocks and votes below the finalized slot.
+//
+// Ancestor blocks are kept so the canonical chain can still be served to peers.
+// Only the proto-array is walked: it holds the previous finalized block and
+// its descendants, which is everything that can have become prunable since.
+func (c *Store) pruneLocked() {
+	finalized := c.latestFinalized
+	c.Slasher.Prune(finalized.Slot)
+	start, ok := c.protoArray.indices[finalized.Root]
+	if !ok {
+		return
+	}
+
+	nodes, indices := c.protoArray.nodes, c.protoArray.indices
+	ancestors := make(map[int]bool)
+	for i := nodes[start].parent; i >= 0; i = nodes[i].parent {
+		ancestors[i] = true
+	}
+	remap := c.protoArray.prune(finalized.Root)
+
+	var prunedBlocks, prunedStates int
+	for i, n := range nodes {
+		if remap[i] >= 0 {
+			continue
+		}
+		if !ancestors[i] {
+			c.storage.DeleteBlock(n.root)
+			prunedBlocks++
+		}
+		c.storage.DeleteState(n.root)
+		prunedStates++
+	}
+
+	prunedAtts := pruneAttestations(c.latestKnownAttestations, indices, remap, finalized.Slot) +
+		pruneAttestations(c.latestNewAttestations, indices, remap, finalized.Slot)
+
+	// Carry the vote weights over to the compacted node indices.
+	c.knownVotes.remap(remap)
+	c.newVotes.remap(remap)
+
+	metrics.ForkChoicePrunedBlocks.Add(float64(prunedBlocks))
+	metrics.ForkChoicePrunedStates.Add(float64(prunedStates))
+	metrics.ForkChoicePrunedAttestations.Add(float64(prunedAtts))
+	log.Info("pruned fork choice store",
+		"finalized_slot", finalized.Slot,
+		"blocks", prunedBlocks,
+		"states", prunedStates,
+		"attestations", prunedAtts,
+	)
+}
+
+// pruneAttestations removes votes that can no longer influence fork choice
+// and returns how many were removed. indices are the node indices of the
+// proto-array before pruning and remap maps them to the pruned array.
+func pruneAttestations(atts map[uint64]*types.SignedAttestation, indices map[[32]byte]int, remap []int, finalizedSlot uint64) int {
+	pruned := 0
+	for id, sa := range atts {
+		head := sa.Message.Data.Head
+		idx, known := indices[head.Root]
+		if head.Slot < finalizedSlot || (known && remap[idx] < 0) {
+			delete(atts, id)
+			pruned++
+		}
+	}
+	return pruned
+}
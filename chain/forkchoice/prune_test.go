@@ -0,0 +1,78 @@
+package forkchoice
+
+import (
+	"testing"
+
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func TestPruneLockedDropsNonCanonicalData(t *testing.T) {
+	db := memory.New()
+	put := func(root, parent byte, slot uint64) [32]byte {
+		r := [32]byte{root}
+		db.PutBlock(r, &types.Block{Slot: slot, ParentRoot: [32]byte{parent}})
+		db.PutState(r, &types.State{Slot: slot})
+		return r
+	}
+
+	// genesis(1) <- a(2) <- fin(3) <- child(4)
+	//           \<- fork(5)
+	genesis := put(1, 0, 0)
+	a := put(2, 1, 1)
+	fin := put(3, 2, 2)
+	child := put(4, 3, 3)
+	fork := put(5, 1, 2)
+
+	vote := func(head [32]byte, slot uint64) *types.SignedAttestation {
+		return &types.SignedAttestation{Message: &types.Attestation{Data: &types.AttestationData{
+			Head: &types.Checkpoint{Root: head, Slot: slot},
+		}}}
+	}
+
+	c := &Store{
+		storage:         db,
+		protoArray:      newProtoArray(db.GetAllBlocks()),
+		knownVotes:      newVoteTracker(),
+		newVotes:        newVoteTracker(),
+		latestFinalized: &types.Checkpoint{Root: fin, Slot: 2},
+		latestKnownAttestations: map[uint64]*types.SignedAttestation{
+			0: vote(child, 3),
+			1: vote(fork, 2),
+			2: vote(a, 1),
+		},
+		latestNewAttestations: map[uint64]*types.SignedAttestation{
+			0: vote(fin, 2),
+		},
+	}
+	c.pruneLocked()
+
+	for _, root := range [][32]byte{genesis, a, fin, child} {
+		if _, ok := db.GetBlock(root); !ok {
+			t.Fatalf("block %x pruned, want kept", root[0])
+		}
+	}
+	if _, ok := db.GetBlock(fork); ok {
+		t.Fatal("fork block kept, want pruned")
+	}
+	for _, root := range [][32]byte{fin, child} {
+		if _, ok := db.GetState(root); !ok {
+			t.Fatalf("state %x pruned, want kept", root[0])
+		}
+	}
+	for _, root := range [][32]byte{genesis, a, fork} {
+		if _, ok := db.GetState(root); ok {
+			t.Fatalf("state %x kept, want pruned", root[0])
+		}
+	}
+
+	if len(c.protoArray.nodes) != 2 || c.protoArray.nodes[0].root != fin || c.protoArray.nodes[1].root != child {
+		t.Fatalf("proto-array nodes = %d, want fin and child", len(c.protoArray.nodes))
+	}
+	if len(c.latestKnownAttestations) != 1 || c.latestKnownAttestations[0] == nil {
+		t.Fatalf("known attestations = %d, want only validator 0", len(c.latestKnownAttestations))
+	}
+	if len(c.latestNewAttestations) != 1 {
+		t.Fatalf("new attestations = %d, want 1", len(c.latestNewAttestations))
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !skip_sig_verify
+
+package forkchoice_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/sigverify"
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/storage/memory"
+)
+
+func TestProcessBlockRejectsInvalidSignature(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	envelope, err := producer.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.Verifier = sigverify.NewWithFunc(1, func([]byte, uint32, [32]byte, []byte) error {
+		return errors.New("bad signature")
+	})
+	if err := fc.ProcessBlock(envelope); err == nil {
+		t.Fatal("expected block with invalid proposer signature to be rejected")
+	}
+	if fc.GetStatus().HeadSlot != 0 {
+		t.Fatalf("head slot = %d, want 0", fc.GetStatus().HeadSlot)
+	}
+
+	fc.Verifier = sigverify.NewWithFunc(1, func([]byte, uint32, [32]byte, []byte) error { return nil })
+	if err := fc.ProcessBlock(envelope); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	root, _ := envelope.Message.Block.HashTreeRoot()
+	if _, ok := fc.GetBlock(root); !ok {
+		t.Fatal("expected block to be imported")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/chain/sigverify"
+	"github.com/geanlabs/gean/chain/slasher"
 	"github.com/geanlabs/gean/observability/logging"
 	"github.com/geanlabs/gean/storage"
 	"github.com/geanlabs/gean/types"
@@ -28,7 +31,34 @@ type Store struct {
 	latestKnownAttestations map[uint64]*types.SignedAttestation
 	latestNewAttestations   map[uint64]*types.SignedAttestation
 
+	// protoArray mirrors the block tree; knownVotes and newVotes track the
+	// weights of latestKnownAttestations and latestNewAttestations on it.
+	protoArray *protoArray
+	knownVotes *voteTracker
+	newVotes   *voteTracker
+
+	// persisted is the last checkpoint set written to storage.
+	persisted *storage.Checkpoints
+
+	// pendingAttestations holds verified gossip votes waiting for an
+	// unknown block, keyed by its root; pendingValidators maps each
+	// validator with a vote there to the root it waits on.
+	pendingAttestations map[[32]byte][]*types.SignedAttestation
+	pendingValidators   map[uint64][32]byte
+
 	NowFn func() uint64
+	// Events, if set, receives head, block, attestation, checkpoint and reorg events.
+	Events *events.Feed
+	// Verifier checks block and attestation signatures outside the store lock.
+	Verifier *sigverify.Verifier
+	// Slasher, if set, is shown every imported block and accepted vote.
+	Slasher *slasher.Slasher
+	// SigningGuard, if set, is consulted before ProduceBlock and
+	// ProduceAttestation sign anything.
+	SigningGuard SigningGuard
+	// FetchBlock, if set, is called in a new goroutine with the root of an
+	// unknown block that attestations have been deferred on.
+	FetchBlock func(root [32]byte)
 }
 
 // ChainStatus is a snapshot of the fork choice head and checkpoint state.
@@ -74,6 +104,77 @@ func (c *Store) GetSignedBlock(root [32]byte) (*types.SignedBlockWithAttestation
 	return c.storage.GetSignedBlock(root)
 }
 
+// GetState retrieves the post-state of a block by its root hash.
+func (c *Store) GetState(root [32]byte) (*types.State, bool) {
+	return c.storage.GetState(root)
+}
+
+// GetCanonicalBlocks returns the signed blocks of the canonical chain with
+// slots in [startSlot, startSlot+count), oldest first. Empty slots and blocks
+// not held locally are skipped.
+func (c *Store) GetCanonicalBlocks(startSlot, count uint64) []*types.SignedBlockWithAttestation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	headBlock, ok := c.storage.GetBlock(c.head)
+	if !ok {
+		return nil
+	}
+	headState, ok := c.storage.GetState(c.head)
+	if !ok {
+		return nil
+	}
+
+	// The head state records the root of every earlier slot (zero if empty).
+	var blocks []*types.SignedBlockWithAttestation
+	for slot := startSlot; slot-startSlot < count && slot <= headBlock.Slot; slot++ {
+		root := c.head
+		if slot < headBlock.Slot {
+			if slot >= uint64(len(headState.HistoricalBlockHashes)) {
+				break
+			}
+			root = headState.HistoricalBlockHashes[slot]
+		}
+		if root == types.ZeroHash {
+			continue
+		}
+		if sb, ok := c.storage.GetSignedBlock(root); ok {
+			blocks = append(blocks, sb)
+		}
+	}
+	return blocks
+}
+
+// CheckFinalized returns an error if cp, typically a peer's finalized
+// checkpoint, conflicts with our finalized chain: it is at or below our
+// finalized slot but is not the block our finalized chain has at that slot.
+// Checkpoints above our finalized slot cannot be judged yet and pass.
+func (c *Store) CheckFinalized(cp *types.Checkpoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	finalized := c.latestFinalized
+	switch {
+	case cp.Slot > finalized.Slot:
+		return nil
+	case cp.Slot == finalized.Slot:
+		if cp.Root != finalized.Root {
+			return fmt.Errorf("finalized root %x at slot %d, ours is %x", cp.Root, cp.Slot, finalized.Root)
+		}
+		return nil
+	}
+
+	// The finalized state records the root of every earlier slot (zero if empty).
+	state, ok := c.storage.GetState(finalized.Root)
+	if !ok || cp.Slot >= uint64(len(state.HistoricalBlockHashes)) {
+		return nil
+	}
+	if ours := state.HistoricalBlockHashes[cp.Slot]; cp.Root != ours {
+		return fmt.Errorf("finalized root %x at slot %d, ours is %x", cp.Root, cp.Slot, ours)
+	}
+	return nil
+}
+
 // GetKnownAttestation returns the latest known attestation for a validator.
 func (c *Store) GetKnownAttestation(validator uint64) (*types.SignedAttestation, bool) {
 	c.mu.Lock()
@@ -99,13 +200,11 @@ func NewStore(state *types.State, anchorBlock *types.Block, store storage.Store)
 
 	anchorRoot, _ := anchorBlock.HashTreeRoot()
 
-	store.PutBlock(anchorRoot, anchorBlock)
-	store.PutSignedBlock(anchorRoot, &types.SignedBlockWithAttestation{
+	store.PutBlockWithState(anchorRoot, &types.SignedBlockWithAttestation{
 		Message: &types.BlockWithAttestation{Block: anchorBlock},
-	})
-	store.PutState(anchorRoot, state)
+	}, state)
 
-	return &Store{
+	c := &Store{
 		time:                    anchorBlock.Slot * types.SecondsPerSlot,
 		genesisTime:             state.Config.GenesisTime,
 		numValidators:           uint64(len(state.Validators)),
@@ -116,5 +215,79 @@ func NewStore(state *types.State, anchorBlock *types.Block, store storage.Store)
 		storage:                 store,
 		latestKnownAttestations: make(map[uint64]*types.SignedAttestation),
 		latestNewAttestations:   make(map[uint64]*types.SignedAttestation),
+		protoArray:              newProtoArray(map[[32]byte]*types.Block{anchorRoot: anchorBlock}),
+		knownVotes:              newVoteTracker(),
+		newVotes:                newVoteTracker(),
+		Verifier:                sigverify.New(0),
+	}
+	c.persistCheckpointsLocked()
+	return c
+}
+
+// RestoreStore resumes a store from the head, justified and finalized
+// checkpoints persisted in the given storage by a previous run.
+func RestoreStore(store storage.Store) (*Store, error) {
+	cps, ok := store.GetCheckpoints()
+	if !ok {
+		return nil, fmt.Errorf("no persisted checkpoints")
+	}
+	headBlock, ok := store.GetBlock(cps.Head.Root)
+	if !ok {
+		return nil, fmt.Errorf("head block %x not found", cps.Head.Root)
+	}
+	headState, ok := store.GetState(cps.Head.Root)
+	if !ok {
+		return nil, fmt.Errorf("head state %x not found", cps.Head.Root)
+	}
+	if _, ok := store.GetBlock(cps.Justified.Root); !ok {
+		return nil, fmt.Errorf("justified block %x not found", cps.Justified.Root)
+	}
+	if _, ok := store.GetBlock(cps.Finalized.Root); !ok {
+		return nil, fmt.Errorf("finalized block %x not found", cps.Finalized.Root)
+	}
+
+	protoArray := newProtoArray(store.GetAllBlocks())
+	protoArray.prune(cps.Finalized.Root)
+
+	return &Store{
+		time:                    headBlock.Slot * types.SecondsPerSlot,
+		genesisTime:             headState.Config.GenesisTime,
+		numValidators:           uint64(len(headState.Validators)),
+		head:                    cps.Head.Root,
+		safeTarget:              cps.Justified.Root,
+		latestJustified:         cps.Justified,
+		latestFinalized:         cps.Finalized,
+		storage:                 store,
+		latestKnownAttestations: make(map[uint64]*types.SignedAttestation),
+		latestNewAttestations:   make(map[uint64]*types.SignedAttestation),
+		protoArray:              protoArray,
+		knownVotes:              newVoteTracker(),
+		newVotes:                newVoteTracker(),
+		Verifier:                sigverify.New(0),
+	}, nil
+}
+
+// GenesisTime returns the genesis time of the chain in unix seconds.
+func (c *Store) GenesisTime() uint64 {
+	return c.genesisTime
+}
+
+// persistCheckpointsLocked writes the current head and checkpoints to
+// storage so a restarted node can resume from them.
+func (c *Store) persistCheckpointsLocked() {
+	headSlot := uint64(0)
+	if hb, ok := c.storage.GetBlock(c.head); ok {
+		headSlot = hb.Slot
+	}
+	head := &types.Checkpoint{Root: c.head, Slot: headSlot}
+	if p := c.persisted; p != nil && *p.Head == *head &&
+		*p.Justified == *c.latestJustified && *p.Finalized == *c.latestFinalized {
+		return
+	}
+	c.persisted = &storage.Checkpoints{
+		Head:      head,
+		Justified: c.latestJustified,
+		Finalized: c.latestFinalized,
 	}
+	c.storage.PutCheckpoints(c.persisted)
 }
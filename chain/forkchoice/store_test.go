@@ -0,0 +1,190 @@
+package forkchoice_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/slasher"
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func TestRestoreStoreResumesFromPersistedCheckpoints(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	db := memory.New()
+	fc := forkchoice.NewStore(state, genesis, db)
+	want := fc.GetStatus()
+
+	restored, err := forkchoice.RestoreStore(db)
+	if err != nil {
+		t.Fatalf("RestoreStore: %v", err)
+	}
+	if got := restored.GetStatus(); got != want {
+		t.Fatalf("status mismatch:\n  got:  %+v\n  want: %+v", got, want)
+	}
+	if restored.GenesisTime() != 1000 {
+		t.Fatalf("genesis time = %d, want 1000", restored.GenesisTime())
+	}
+	if restored.NumValidators() != 3 {
+		t.Fatalf("num validators = %d, want 3", restored.NumValidators())
+	}
+}
+
+func TestRestoreStoreWithoutCheckpointsFails(t *testing.T) {
+	if _, err := forkchoice.RestoreStore(memory.New()); err == nil {
+		t.Fatal("expected error restoring from empty storage")
+	}
+}
+
+func TestGetCanonicalBlocksSkipsEmptySlots(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	for _, slot := range []uint64{1, 2, 4} {
+		if _, err := fc.ProduceBlock(slot, slot%3, testutil.Signer{}); err != nil {
+			t.Fatalf("ProduceBlock(%d): %v", slot, err)
+		}
+	}
+	fc.AcceptNewAttestations()
+
+	slotsOf := func(blocks []*types.SignedBlockWithAttestation) []uint64 {
+		var slots []uint64
+		for _, sb := range blocks {
+			slots = append(slots, sb.Message.Block.Slot)
+		}
+		return slots
+	}
+
+	if got := slotsOf(fc.GetCanonicalBlocks(0, 10)); fmt.Sprint(got) != "[0 1 2 4]" {
+		t.Fatalf("GetCanonicalBlocks(0, 10) slots = %v, want [0 1 2 4]", got)
+	}
+	if got := slotsOf(fc.GetCanonicalBlocks(2, 2)); fmt.Sprint(got) != "[2]" {
+		t.Fatalf("GetCanonicalBlocks(2, 2) slots = %v, want [2]", got)
+	}
+}
+
+type refuseGuard struct{}
+
+func (refuseGuard) CheckBlock(uint64, uint64) error { return errors.New("refused") }
+
+func (refuseGuard) CheckAttestation(uint64, *types.AttestationData) error {
+	return errors.New("refused")
+}
+
+type countingSigner struct{ calls int }
+
+func (s *countingSigner) Sign(uint32, [32]byte) ([]byte, error) {
+	s.calls++
+	return make([]byte, 3112), nil
+}
+
+func TestSigningGuardRefusesBeforeSigning(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.SigningGuard = refuseGuard{}
+	signer := &countingSigner{}
+
+	if _, err := fc.ProduceBlock(1, 1, signer); err == nil {
+		t.Fatal("expected ProduceBlock to be refused")
+	}
+	if _, err := fc.ProduceAttestation(1, 0, signer); err == nil {
+		t.Fatal("expected ProduceAttestation to be refused")
+	}
+	if signer.calls != 0 {
+		t.Fatalf("signer calls = %d, want 0", signer.calls)
+	}
+}
+
+func TestProduceBlockPublishesBlockEvent(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.Events = events.NewFeed()
+	sub := fc.Events.Subscribe(events.TopicBlock)
+	defer sub.Unsubscribe()
+
+	sb, err := fc.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	root, _ := sb.Message.Block.HashTreeRoot()
+	ev := <-sub.C
+	if block, ok := ev.Data.(events.BlockEvent); !ok || block.Root != root || block.ProposerIndex != 1 {
+		t.Fatalf("event = %+v, want block %x by proposer 1", ev, root)
+	}
+}
+
+func TestProduceBlockAppliesBlockLocally(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+
+	sb, err := fc.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	root, _ := sb.Message.Block.HashTreeRoot()
+	if head := fc.GetStatus().Head; head != root {
+		t.Fatalf("head = %x, want produced block %x", head, root)
+	}
+	if sa, ok := fc.GetNewAttestation(1); !ok || sa.Message.Data.Head.Root != root {
+		t.Fatal("proposer attestation not counted as a new vote")
+	}
+}
+
+func TestProduceBlockChecksForEquivocation(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	db := memory.New()
+	fc := forkchoice.NewStore(state, genesis, db)
+	fc.Slasher = slasher.New(db, nil)
+
+	other := forkchoice.NewStore(state, genesis, memory.New())
+	if _, err := other.ProduceBlock(1, 1, testutil.Signer{}); err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	first, err := other.ProduceBlock(2, 2, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	if _, err := fc.ProduceBlock(2, 2, testutil.Signer{}); err != nil {
+		t.Fatalf("ProduceBlock: %v", err)
+	}
+	parent, _ := other.GetSignedBlock(first.Message.Block.ParentRoot)
+	if err := fc.ProcessBlock(parent); err != nil {
+		t.Fatalf("ProcessBlock(parent): %v", err)
+	}
+	if err := fc.ProcessBlock(first); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+
+	// The two blocks carry conflicting proposer attestations, too.
+	kinds := map[storage.EvidenceKind]bool{}
+	for _, ev := range fc.Slasher.Evidence() {
+		if ev.Validator != 2 {
+			t.Fatalf("evidence against validator %d, want 2", ev.Validator)
+		}
+		kinds[ev.Kind] = true
+	}
+	if len(kinds) != 2 || !kinds[storage.ProposerEquivocation] || !kinds[storage.DoubleVote] {
+		t.Fatalf("evidence kinds = %v, want proposer equivocation and double vote", kinds)
+	}
+}
+
+func TestCheckFinalizedRejectsConflictingCheckpoint(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	genesisRoot, _ := genesis.HashTreeRoot()
+
+	if err := fc.CheckFinalized(&types.Checkpoint{Root: genesisRoot, Slot: 0}); err != nil {
+		t.Fatalf("CheckFinalized(our genesis) = %v, want nil", err)
+	}
+	if err := fc.CheckFinalized(&types.Checkpoint{Root: [32]byte{0x01}, Slot: 0}); err == nil {
+		t.Fatal("CheckFinalized accepted a different genesis")
+	}
+	// A checkpoint beyond our finalized slot cannot be judged yet.
+	if err := fc.CheckFinalized(&types.Checkpoint{Root: [32]byte{0x01}, Slot: 5}); err != nil {
+		t.Fatalf("CheckFinalized(ahead) = %v, want nil", err)
+	}
+}
@@ -1,6 +1,7 @@
 package forkchoice
 
 import (
+	"github.com/geanlabs/gean/chain/events"
 	"github.com/geanlabs/gean/observability/metrics"
 	"github.com/geanlabs/gean/types"
 )
@@ -36,6 +37,7 @@ func (c *Store) tickIntervalLocked(hasProposal bool) {
 
 	switch currentInterval {
 	case 0:
+		c.expirePendingAttestationsLocked()
 		if hasProposal {
 			c.acceptNewAttestationsLocked()
 		}
@@ -64,7 +66,57 @@ func (c *Store) acceptNewAttestationsLocked() {
 }
 
 func (c *Store) updateHeadLocked() {
-	c.head = GetForkChoiceHead(c.storage, c.latestJustified.Root, c.latestKnownAttestations, 0)
+	oldHead := c.head
+	c.knownVotes.update(c.protoArray, c.latestKnownAttestations)
+	c.head = c.protoArray.findHead(c.latestJustified.Root, c.knownVotes, 0)
+	if c.head != oldHead {
+		c.publishHeadLocked(oldHead)
+	}
+	c.persistCheckpointsLocked()
+}
+
+// publishHeadLocked publishes a head event, and a reorg event when the new
+// head is not a descendant of oldHead.
+func (c *Store) publishHeadLocked(oldHead [32]byte) {
+	if c.Events == nil {
+		return
+	}
+	newBlock, ok := c.storage.GetBlock(c.head)
+	if !ok {
+		return
+	}
+	c.Events.Publish(events.TopicHead, events.HeadEvent{Slot: newBlock.Slot, Root: c.head})
+
+	ancestor, depth, ok := c.commonAncestorLocked(oldHead, c.head)
+	if ok && ancestor != oldHead {
+		c.Events.Publish(events.TopicReorg, events.ReorgEvent{
+			Slot:           newBlock.Slot,
+			Depth:          depth,
+			OldHead:        oldHead,
+			NewHead:        c.head,
+			CommonAncestor: ancestor,
+		})
+	}
+}
+
+// commonAncestorLocked returns the closest common ancestor of a and b and the
+// number of blocks between a and that ancestor.
+func (c *Store) commonAncestorLocked(a, b [32]byte) ([32]byte, uint64, bool) {
+	var depth uint64
+	for a != b {
+		blockA, okA := c.storage.GetBlock(a)
+		blockB, okB := c.storage.GetBlock(b)
+		if !okA || !okB {
+			return [32]byte{}, 0, false
+		}
+		if blockA.Slot >= blockB.Slot {
+			a = blockA.ParentRoot
+			depth++
+		} else {
+			b = blockB.ParentRoot
+		}
+	}
+	return a, depth, true
 }
 
 // UpdateSafeTarget finds the head with sufficient (2/3+) vote support.
@@ -76,7 +128,8 @@ func (c *Store) UpdateSafeTarget() {
 
 func (c *Store) updateSafeTargetLocked() {
 	minScore := int(ceilDiv(c.numValidators*2, 3))
-	c.safeTarget = GetForkChoiceHead(c.storage, c.latestJustified.Root, c.latestNewAttestations, minScore)
+	c.newVotes.update(c.protoArray, c.latestNewAttestations)
+	c.safeTarget = c.protoArray.findHead(c.latestJustified.Root, c.newVotes, minScore)
 	if block, ok := c.storage.GetBlock(c.safeTarget); ok {
 		metrics.SafeTargetSlot.Set(float64(block.Slot))
 	}
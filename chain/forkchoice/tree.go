@@ -0,0 +1,52 @@
+package forkchoice
+
+import "github.com/geanlabs/gean/types"
+
+// Tree is a snapshot of the fork choice block tree, for debugging.
+type Tree struct {
+	Head       [32]byte
+	SafeTarget [32]byte
+	Justified  types.Checkpoint
+	Finalized  types.Checkpoint
+	// Nodes lists the finalized block and its descendants, parents before
+	// children.
+	Nodes []TreeNode
+}
+
+// TreeNode is one block in a Tree.
+type TreeNode struct {
+	Root          [32]byte
+	ParentRoot    [32]byte
+	Slot          uint64
+	ProposerIndex uint64
+	// Weight is the number of latest known votes for the block or one of
+	// its descendants, as of the last head update. Votes accepted since
+	// are counted from the next one.
+	Weight int
+}
+
+// GetTree returns the block tree from the finalized block with the vote
+// weight of every block, the head, the safe target and the latest justified
+// and finalized checkpoints. It reads only the in-memory proto-array.
+func (c *Store) GetTree() Tree {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tree := Tree{
+		Head:       c.head,
+		SafeTarget: c.safeTarget,
+		Justified:  *c.latestJustified,
+		Finalized:  *c.latestFinalized,
+		Nodes:      make([]TreeNode, len(c.protoArray.nodes)),
+	}
+	for i, n := range c.protoArray.nodes {
+		tree.Nodes[i] = TreeNode{
+			Root:          n.root,
+			ParentRoot:    n.parentRoot,
+			Slot:          n.slot,
+			ProposerIndex: n.proposer,
+			Weight:        c.knownVotes.weight(i),
+		}
+	}
+	return tree
+}
@@ -0,0 +1,50 @@
+package forkchoice_test
+
+import (
+	"testing"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func TestGetTreeReportsBlocksAndWeights(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	genesisRoot, _ := genesis.HashTreeRoot()
+	for slot := uint64(1); slot <= 2; slot++ {
+		if _, err := fc.ProduceBlock(slot, slot, testutil.Signer{}); err != nil {
+			t.Fatalf("ProduceBlock(%d): %v", slot, err)
+		}
+	}
+	fc.NowFn = func() uint64 { return 1000 + 2*types.SecondsPerSlot }
+	for validator := uint64(0); validator < 2; validator++ {
+		sa, err := fc.ProduceAttestation(2, validator, testutil.Signer{})
+		if err != nil {
+			t.Fatalf("ProduceAttestation(%d): %v", validator, err)
+		}
+		fc.ProcessAttestation(sa)
+	}
+	fc.AcceptNewAttestations()
+
+	tree := fc.GetTree()
+	if len(tree.Nodes) != 3 {
+		t.Fatalf("nodes = %d, want 3", len(tree.Nodes))
+	}
+	if tree.Nodes[0].Root != genesisRoot || tree.Finalized.Root != genesisRoot {
+		t.Fatalf("first node = %x, finalized = %x, want genesis %x", tree.Nodes[0].Root, tree.Finalized.Root, genesisRoot)
+	}
+	head := tree.Nodes[2]
+	if head.Root != tree.Head || head.Slot != 2 || head.ProposerIndex != 2 {
+		t.Fatalf("last node = %+v, want head %x at slot 2 by proposer 2", head, tree.Head)
+	}
+	if head.ParentRoot != tree.Nodes[1].Root || tree.Nodes[1].ParentRoot != genesisRoot {
+		t.Fatal("nodes do not link back to genesis")
+	}
+	// Validators 0 and 1 attested to the head; validator 2 did as its
+	// proposer.
+	if head.Weight != 3 || tree.Nodes[0].Weight != 3 {
+		t.Fatalf("head weight = %d, genesis weight = %d, want 3 votes", head.Weight, tree.Nodes[0].Weight)
+	}
+}
@@ -0,0 +1,132 @@
+package forkchoice
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/geanlabs/gean/chain/statetransition"
+	"github.com/geanlabs/gean/types"
+)
+
+// ErrInvalid marks a gossip message that can never become valid, as opposed
+// to one that is merely useless now (already known, too old, or waiting on
+// an unknown block). The former should be rejected and its sender
+// penalized; the latter is just not propagated.
+var ErrInvalid = errors.New("invalid")
+
+// ErrUnknownParent marks a block whose parent has not been imported. The
+// block may become valid once the parent is fetched.
+var ErrUnknownParent = errors.New("unknown parent")
+
+// ErrUnknownBlock marks an attestation whose head, target or source block
+// has not been imported. It may be deferred until the block arrives.
+var ErrUnknownBlock = errors.New("unknown block")
+
+// ValidateGossipBlock runs the checks a block must pass before it is
+// forwarded to peers. Signatures and the state transition are checked on
+// import by ProcessBlock.
+func (c *Store) ValidateGossipBlock(sb *types.SignedBlockWithAttestation) error {
+	if sb.Message == nil || sb.Message.Block == nil || sb.Message.Block.Body == nil {
+		return fmt.Errorf("%w: missing block", ErrInvalid)
+	}
+	block := sb.Message.Block
+	numSigs := len(block.Body.Attestations)
+	if sb.Message.ProposerAttestation != nil {
+		if sb.Message.ProposerAttestation.ValidatorID != block.ProposerIndex {
+			return fmt.Errorf("%w: proposer attestation from validator %d, proposer is %d",
+				ErrInvalid, sb.Message.ProposerAttestation.ValidatorID, block.ProposerIndex)
+		}
+		numSigs++
+	}
+	if len(sb.Signature) != numSigs {
+		return fmt.Errorf("%w: signature count %d, want %d", ErrInvalid, len(sb.Signature), numSigs)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.NowFn != nil {
+		c.advanceTimeLocked(c.NowFn(), false)
+	}
+
+	if block.ProposerIndex >= c.numValidators || !statetransition.IsProposer(block.ProposerIndex, block.Slot, c.numValidators) {
+		return fmt.Errorf("%w: validator %d is not proposer for slot %d", ErrInvalid, block.ProposerIndex, block.Slot)
+	}
+	if currentSlot := c.time / types.IntervalsPerSlot; block.Slot > currentSlot+1 {
+		return fmt.Errorf("block slot %d too far in future (current %d)", block.Slot, currentSlot)
+	}
+	if block.Slot <= c.latestFinalized.Slot {
+		return fmt.Errorf("block slot %d at or before finalized slot %d", block.Slot, c.latestFinalized.Slot)
+	}
+	blockHash, _ := block.HashTreeRoot()
+	if _, ok := c.storage.GetBlock(blockHash); ok {
+		return fmt.Errorf("block already known")
+	}
+	parent, ok := c.storage.GetBlock(block.ParentRoot)
+	if !ok {
+		return fmt.Errorf("%w %x", ErrUnknownParent, block.ParentRoot)
+	}
+	if parent.Slot >= block.Slot {
+		return fmt.Errorf("%w: block slot %d not after parent slot %d", ErrInvalid, block.Slot, parent.Slot)
+	}
+	return nil
+}
+
+// ValidateGossipAttestation runs the checks an attestation must pass before
+// it is forwarded to peers.
+func (c *Store) ValidateGossipAttestation(sa *types.SignedAttestation) error {
+	if sa.Message == nil || sa.Message.Data == nil {
+		return fmt.Errorf("%w: missing attestation data", ErrInvalid)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.NowFn != nil {
+		c.advanceTimeLocked(c.NowFn(), false)
+	}
+
+	if sa.Message.ValidatorID >= c.numValidators {
+		return fmt.Errorf("%w: validator index %d out of range", ErrInvalid, sa.Message.ValidatorID)
+	}
+	return c.validateGossipAttestationDataLocked(sa.Message.Data)
+}
+
+// ValidateGossipAggregate runs the checks an aggregated attestation must
+// pass before it is forwarded to peers.
+func (c *Store) ValidateGossipAggregate(agg *types.AggregatedAttestation) error {
+	if agg.Data == nil {
+		return fmt.Errorf("%w: missing attestation data", ErrInvalid)
+	}
+	validatorIDs, _, err := DisaggregateAttestation(agg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if len(validatorIDs) == 0 {
+		return fmt.Errorf("%w: no participants", ErrInvalid)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.NowFn != nil {
+		c.advanceTimeLocked(c.NowFn(), false)
+	}
+
+	if last := validatorIDs[len(validatorIDs)-1]; last >= c.numValidators {
+		return fmt.Errorf("%w: validator index %d out of range", ErrInvalid, last)
+	}
+	return c.validateGossipAttestationDataLocked(agg.Data)
+}
+
+func (c *Store) validateGossipAttestationDataLocked(data *types.AttestationData) error {
+	if data.Head == nil || data.Target == nil || data.Source == nil {
+		return fmt.Errorf("%w: missing checkpoint", ErrInvalid)
+	}
+	if err := c.validateAttestationData(data); err != nil {
+		return err
+	}
+	if currentSlot := c.time / types.IntervalsPerSlot; data.Slot > currentSlot {
+		return fmt.Errorf("attestation slot %d in future (current %d)", data.Slot, currentSlot)
+	}
+	if data.Target.Slot < c.latestFinalized.Slot {
+		return fmt.Errorf("target slot %d before finalized slot %d", data.Target.Slot, c.latestFinalized.Slot)
+	}
+	return nil
+}
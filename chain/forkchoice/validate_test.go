@@ -0,0 +1,80 @@
+package forkchoice_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func TestValidateGossipBlock(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	b1, err := producer.ProduceBlock(1, 1, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock(1): %v", err)
+	}
+	b2, err := producer.ProduceBlock(2, 2, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceBlock(2): %v", err)
+	}
+
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.NowFn = func() uint64 { return 1000 + 2*types.SecondsPerSlot }
+
+	if err := fc.ValidateGossipBlock(b1); err != nil {
+		t.Fatalf("valid block: %v", err)
+	}
+	if err := fc.ValidateGossipBlock(b2); !errors.Is(err, forkchoice.ErrUnknownParent) || errors.Is(err, forkchoice.ErrInvalid) {
+		t.Fatalf("unknown parent: err = %v, want ErrUnknownParent", err)
+	}
+
+	wrongProposer := *b1.Message.Block
+	wrongProposer.ProposerIndex = 2
+	sb := &types.SignedBlockWithAttestation{
+		Message: &types.BlockWithAttestation{
+			Block:               &wrongProposer,
+			ProposerAttestation: &types.Attestation{ValidatorID: 2, Data: b1.Message.ProposerAttestation.Data},
+		},
+		Signature: b1.Signature,
+	}
+	if err := fc.ValidateGossipBlock(sb); !errors.Is(err, forkchoice.ErrInvalid) {
+		t.Fatalf("wrong proposer: err = %v, want ErrInvalid", err)
+	}
+
+	if err := fc.ProcessBlock(b1); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	if err := fc.ValidateGossipBlock(b1); err == nil || errors.Is(err, forkchoice.ErrInvalid) {
+		t.Fatalf("known block: err = %v, want a non-invalid error", err)
+	}
+}
+
+func TestValidateGossipAttestation(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	fc.NowFn = func() uint64 { return 1000 + types.SecondsPerSlot }
+
+	sa, err := fc.ProduceAttestation(1, 0, testutil.Signer{})
+	if err != nil {
+		t.Fatalf("ProduceAttestation: %v", err)
+	}
+	if err := fc.ValidateGossipAttestation(sa); err != nil {
+		t.Fatalf("valid attestation: %v", err)
+	}
+
+	outOfRange := &types.SignedAttestation{Message: &types.Attestation{ValidatorID: 3, Data: sa.Message.Data}}
+	if err := fc.ValidateGossipAttestation(outOfRange); !errors.Is(err, forkchoice.ErrInvalid) {
+		t.Fatalf("validator out of range: err = %v, want ErrInvalid", err)
+	}
+
+	data := *sa.Message.Data
+	data.Head = &types.Checkpoint{Root: [32]byte{0xff}, Slot: 1}
+	unknownHead := &types.SignedAttestation{Message: &types.Attestation{ValidatorID: 0, Data: &data}}
+	if err := fc.ValidateGossipAttestation(unknownHead); err == nil || errors.Is(err, forkchoice.ErrInvalid) {
+		t.Fatalf("unknown head: err = %v, want a non-invalid error", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package pending holds gossip that arrived before the blocks it builds on,
+// until those blocks are fetched and imported.
+package pending
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+const (
+	// DefaultMaxBlocks bounds the block pool.
+	DefaultMaxBlocks = 256
+	// BlockExpirySlots is how many slots a block may wait for its parent.
+	BlockExpirySlots = types.SlotsPerEpoch
+)
+
+// Blocks is a bounded pool of blocks whose parent is not yet known, keyed
+// by parent root so they can be replayed once the parent is imported.
+type Blocks struct {
+	max int
+
+	mu       sync.Mutex
+	byRoot   map[[32]byte]*pendingBlock
+	byParent map[[32]byte][]*pendingBlock
+}
+
+type pendingBlock struct {
+	block *types.SignedBlockWithAttestation
+	root  [32]byte
+	from  peer.ID
+}
+
+// NewBlocks returns an empty pool holding at most max blocks.
+func NewBlocks(max int) *Blocks {
+	return &Blocks{
+		max:      max,
+		byRoot:   make(map[[32]byte]*pendingBlock),
+		byParent: make(map[[32]byte][]*pendingBlock),
+	}
+}
+
+// Add queues a block received from a peer. It returns false if the block
+// is already queued or the pool is full.
+func (p *Blocks) Add(sb *types.SignedBlockWithAttestation, root [32]byte, from peer.ID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.byRoot[root]; ok || len(p.byRoot) >= p.max {
+		return false
+	}
+	pb := &pendingBlock{block: sb, root: root, from: from}
+	p.byRoot[root] = pb
+	parent := sb.Message.Block.ParentRoot
+	p.byParent[parent] = append(p.byParent[parent], pb)
+	metrics.PendingBlocks.Set(float64(len(p.byRoot)))
+	return true
+}
+
+// Has reports whether a block is queued.
+func (p *Blocks) Has(root [32]byte) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.byRoot[root]
+	return ok
+}
+
+// Len returns the number of queued blocks.
+func (p *Blocks) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.byRoot)
+}
+
+// MissingAncestor follows parent roots from root through the pool and
+// returns the first root that is not queued, the block to fetch next, with
+// the peer that sent its queued child.
+func (p *Blocks) MissingAncestor(root [32]byte) ([32]byte, peer.ID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var from peer.ID
+	for {
+		pb, ok := p.byRoot[root]
+		if !ok {
+			return root, from
+		}
+		root, from = pb.block.Message.Block.ParentRoot, pb.from
+	}
+}
+
+// TakeChildren removes and returns the blocks waiting on parent.
+func (p *Blocks) TakeChildren(parent [32]byte) []*types.SignedBlockWithAttestation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	children := p.byParent[parent]
+	delete(p.byParent, parent)
+	blocks := make([]*types.SignedBlockWithAttestation, len(children))
+	for i, pb := range children {
+		delete(p.byRoot, pb.root)
+		blocks[i] = pb.block
+	}
+	metrics.PendingBlocks.Set(float64(len(p.byRoot)))
+	return blocks
+}
+
+// Prune drops blocks that can no longer be imported, at or before
+// finalizedSlot, or that have waited more than BlockExpirySlots behind
+// currentSlot.
+func (p *Blocks) Prune(currentSlot, finalizedSlot uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for root, pb := range p.byRoot {
+		slot := pb.block.Message.Block.Slot
+		if slot > finalizedSlot && slot+BlockExpirySlots >= currentSlot {
+			continue
+		}
+		delete(p.byRoot, root)
+		parent := pb.block.Message.Block.ParentRoot
+		siblings := p.byParent[parent]
+		for i, s := range siblings {
+			if s == pb {
+				siblings = append(siblings[:i], siblings[i+1:]...)
+				break
+			}
+		}
+		if len(siblings) == 0 {
+			delete(p.byParent, parent)
+		} else {
+			p.byParent[parent] = siblings
+		}
+	}
+	metrics.PendingBlocks.Set(float64(len(p.byRoot)))
+}
@@ -0,0 +1,79 @@
+package pending_test
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/chain/pending"
+	"github.com/geanlabs/gean/types"
+)
+
+func makeBlock(slot uint64, parent [32]byte) (*types.SignedBlockWithAttestation, [32]byte) {
+	block := &types.Block{Slot: slot, ParentRoot: parent, Body: &types.BlockBody{}}
+	root, _ := block.HashTreeRoot()
+	return &types.SignedBlockWithAttestation{Message: &types.BlockWithAttestation{Block: block}}, root
+}
+
+func TestBlocksChainsThroughMissingAncestors(t *testing.T) {
+	p := pending.NewBlocks(8)
+	missing := [32]byte{0x01}
+	b2, r2 := makeBlock(2, missing)
+	b3, r3 := makeBlock(3, r2)
+	b3b, _ := makeBlock(3, r2)
+	b3b.Message.Block.ProposerIndex = 1 // a sibling of b3
+	r3b, _ := b3b.Message.Block.HashTreeRoot()
+
+	if !p.Add(b3, r3, peer.ID("a")) || !p.Add(b2, r2, peer.ID("b")) || !p.Add(b3b, r3b, peer.ID("a")) {
+		t.Fatal("Add refused a new block")
+	}
+	if p.Add(b3, r3, peer.ID("a")) {
+		t.Fatal("Add accepted a duplicate")
+	}
+
+	root, from := p.MissingAncestor(r3)
+	if root != missing || from != peer.ID("b") {
+		t.Fatalf("MissingAncestor = %x, %s, want %x, b", root, from, missing)
+	}
+
+	if got := p.TakeChildren(missing); len(got) != 1 || got[0] != b2 {
+		t.Fatalf("TakeChildren(missing) = %d blocks, want b2", len(got))
+	}
+	if got := p.TakeChildren(r2); len(got) != 2 {
+		t.Fatalf("TakeChildren(b2) = %d blocks, want 2", len(got))
+	}
+	if p.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", p.Len())
+	}
+}
+
+func TestBlocksIsBounded(t *testing.T) {
+	p := pending.NewBlocks(1)
+	b1, r1 := makeBlock(1, [32]byte{0x01})
+	b2, r2 := makeBlock(2, [32]byte{0x02})
+	if !p.Add(b1, r1, "") {
+		t.Fatal("Add refused the first block")
+	}
+	if p.Add(b2, r2, "") {
+		t.Fatal("Add accepted a block into a full pool")
+	}
+}
+
+func TestBlocksPruneExpires(t *testing.T) {
+	p := pending.NewBlocks(8)
+	old, oldRoot := makeBlock(1, [32]byte{0x01})
+	finalized, finalizedRoot := makeBlock(40, [32]byte{0x02})
+	fresh, freshRoot := makeBlock(60, [32]byte{0x03})
+	p.Add(old, oldRoot, "")
+	p.Add(finalized, finalizedRoot, "")
+	p.Add(fresh, freshRoot, "")
+
+	p.Prune(60, 40)
+	if p.Has(oldRoot) || p.Has(finalizedRoot) || !p.Has(freshRoot) {
+		t.Fatalf("after Prune: old=%v finalized=%v fresh=%v, want false false true",
+			p.Has(oldRoot), p.Has(finalizedRoot), p.Has(freshRoot))
+	}
+	if got := p.TakeChildren([32]byte{0x01}); len(got) != 0 {
+		t.Fatalf("pruned block still listed under its parent")
+	}
+}
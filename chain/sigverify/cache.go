@@ -0,0 +1,81 @@
+package sigverify
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/geanlabs/gean/observability/metrics"
+)
+
+// DefaultCacheSize is the number of verified signatures New remembers.
+const DefaultCacheSize = 4096
+
+// cacheKey identifies a verified signature: the validator, the message it
+// signed and a digest of the signature bytes.
+type cacheKey struct {
+	validator uint64
+	message   [32]byte
+	sig       [32]byte
+}
+
+func keyOf(job Job) cacheKey {
+	return cacheKey{
+		validator: job.Validator,
+		message:   job.Message,
+		sig:       sha256.Sum256(job.Signature[:]),
+	}
+}
+
+// Cache is a bounded LRU set of signatures that verified successfully, so a
+// vote seen on gossip is not verified again when it shows up in a block.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // front is most recently used
+	items map[cacheKey]*list.Element
+}
+
+// NewCache returns a cache holding up to size signatures.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:  size,
+		order: list.New(),
+		items: make(map[cacheKey]*list.Element, size),
+	}
+}
+
+// Len returns the number of cached signatures.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) contains(k cacheKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[k]
+	if ok {
+		c.order.MoveToFront(e)
+		metrics.SignatureCacheHits.Inc()
+	} else {
+		metrics.SignatureCacheMisses.Inc()
+	}
+	return ok
+}
+
+func (c *Cache) add(k cacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[k]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[k] = c.order.PushFront(k)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(cacheKey))
+	}
+}
@@ -0,0 +1,114 @@
+// Package sigverify verifies batches of XMSS signatures on a bounded pool
+// of workers.
+//
+// XMSS verification is expensive and independent per signature, so the
+// signatures of a block or an aggregate are checked concurrently and the
+// caller gets one result per signature. Callers hold no locks while a batch
+// runs; the pool bound is shared by all of them, so concurrent batches
+// never use more than the configured number of cores between them.
+package sigverify
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+	"github.com/geanlabs/gean/xmss/leansig"
+)
+
+// VerifyFunc checks one signature over message at epoch.
+type VerifyFunc func(pubkey []byte, epoch uint32, message [32]byte, sig []byte) error
+
+// Job is one signature to verify.
+type Job struct {
+	Validator uint64
+	Pubkey    [52]byte
+	Epoch     uint32
+	Message   [32]byte
+	Signature [types.XMSSSignatureSize]byte
+}
+
+// Verifier runs signature checks on at most a fixed number of goroutines.
+type Verifier struct {
+	// Cache, if set, skips signatures that already verified.
+	Cache *Cache
+
+	verify VerifyFunc
+	slots  chan struct{}
+}
+
+// New returns a Verifier using leansig.Verify on up to workers goroutines,
+// with a cache of DefaultCacheSize signatures. workers <= 0 means one per CPU.
+func New(workers int) *Verifier {
+	v := NewWithFunc(workers, leansig.Verify)
+	v.Cache = NewCache(DefaultCacheSize)
+	return v
+}
+
+// NewWithFunc returns a Verifier using fn, for tests and alternative schemes.
+func NewWithFunc(workers int, fn VerifyFunc) *Verifier {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &Verifier{verify: fn, slots: make(chan struct{}, workers)}
+}
+
+// Verify checks a single signature.
+func (v *Verifier) Verify(job Job) error {
+	return v.VerifyBatch([]Job{job})[0]
+}
+
+// VerifyBatch checks every job and returns the result for jobs[i] at index i
+// (nil if valid). Jobs found in the Cache count as valid without being
+// verified again. It returns once all jobs are done.
+func (v *Verifier) VerifyBatch(jobs []Job) []error {
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for i := range jobs {
+		var key cacheKey
+		if v.Cache != nil {
+			key = keyOf(jobs[i])
+			if v.Cache.contains(key) {
+				continue
+			}
+		}
+		v.slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-v.slots
+				wg.Done()
+			}()
+			start := time.Now()
+			errs[i] = v.verify(jobs[i].Pubkey[:], jobs[i].Epoch, jobs[i].Message, jobs[i].Signature[:])
+			metrics.SignatureVerificationTime.Observe(time.Since(start).Seconds())
+			if errs[i] == nil && v.Cache != nil {
+				v.Cache.add(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// AttestationJob builds the job for an attestation signed by one validator,
+// looking up the validator's key in state.
+func AttestationJob(state *types.State, att *types.Attestation, sig [types.XMSSSignatureSize]byte) (Job, error) {
+	if att.ValidatorID >= uint64(len(state.Validators)) {
+		return Job{}, fmt.Errorf("invalid validator index %d", att.ValidatorID)
+	}
+	messageRoot, err := att.HashTreeRoot()
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to hash attestation message: %w", err)
+	}
+	return Job{
+		Validator: att.ValidatorID,
+		Pubkey:    state.Validators[att.ValidatorID].Pubkey,
+		Epoch:     uint32(att.Data.Slot),
+		Message:   messageRoot,
+		Signature: sig,
+	}, nil
+}
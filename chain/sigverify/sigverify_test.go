@@ -0,0 +1,128 @@
+package sigverify_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/geanlabs/gean/chain/sigverify"
+)
+
+func TestVerifyBatchReturnsPerIndexResults(t *testing.T) {
+	bad := errors.New("bad signature")
+	v := sigverify.NewWithFunc(4, func(_ []byte, epoch uint32, _ [32]byte, _ []byte) error {
+		if epoch%3 == 0 {
+			return bad
+		}
+		return nil
+	})
+
+	jobs := make([]sigverify.Job, 10)
+	for i := range jobs {
+		jobs[i].Epoch = uint32(i)
+	}
+	errs := v.VerifyBatch(jobs)
+	if len(errs) != len(jobs) {
+		t.Fatalf("results = %d, want %d", len(errs), len(jobs))
+	}
+	for i, err := range errs {
+		if want := i%3 == 0; (err != nil) != want {
+			t.Fatalf("job %d: err = %v, want failure %v", i, err, want)
+		}
+	}
+}
+
+func TestVerifyBatchBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	v := sigverify.NewWithFunc(2, func([]byte, uint32, [32]byte, []byte) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+
+	done := make(chan struct{})
+	for range 3 {
+		go func() {
+			v.VerifyBatch(make([]sigverify.Job, 8))
+			done <- struct{}{}
+		}()
+	}
+	for range 3 {
+		<-done
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("peak concurrent verifications = %d, want <= 2", got)
+	}
+}
+
+func TestCacheSkipsVerifiedSignatures(t *testing.T) {
+	var calls atomic.Int32
+	v := sigverify.NewWithFunc(1, func([]byte, uint32, [32]byte, []byte) error {
+		calls.Add(1)
+		return nil
+	})
+	v.Cache = sigverify.NewCache(2)
+
+	a := sigverify.Job{Validator: 1, Message: [32]byte{1}}
+	b := sigverify.Job{Validator: 2, Message: [32]byte{2}}
+	c := sigverify.Job{Validator: 3, Message: [32]byte{3}}
+
+	v.VerifyBatch([]sigverify.Job{a, b})
+	v.VerifyBatch([]sigverify.Job{a, b})
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("verify calls = %d, want 2", got)
+	}
+
+	// Touch a so that b is least recently used, then evict b with c.
+	v.Verify(a)
+	v.Verify(c)
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("verify calls = %d, want 3", got)
+	}
+	v.Verify(a)
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("verify calls after re-verifying a = %d, want 3", got)
+	}
+	v.Verify(b)
+	if got := calls.Load(); got != 4 {
+		t.Fatalf("verify calls after re-verifying evicted b = %d, want 4", got)
+	}
+
+	// A different signature over the same message is not a hit.
+	other := a
+	other.Signature[0] = 0xff
+	v.Verify(other)
+	if got := calls.Load(); got != 5 {
+		t.Fatalf("verify calls = %d, want 5", got)
+	}
+	if v.Cache.Len() != 2 {
+		t.Fatalf("cache len = %d, want 2", v.Cache.Len())
+	}
+}
+
+func TestCacheIgnoresFailures(t *testing.T) {
+	var calls atomic.Int32
+	v := sigverify.NewWithFunc(1, func([]byte, uint32, [32]byte, []byte) error {
+		calls.Add(1)
+		return errors.New("bad signature")
+	})
+	v.Cache = sigverify.NewCache(4)
+
+	job := sigverify.Job{Validator: 1}
+	for range 2 {
+		if err := v.Verify(job); err == nil {
+			t.Fatal("expected verification to fail")
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("verify calls = %d, want 2", got)
+	}
+}
@@ -0,0 +1,196 @@
+// Package slasher watches imported blocks and accepted attestations for
+// validators that equivocate: proposers that sign two blocks for one slot,
+// and attesters that cast double or surround votes. The conflicting signed
+// messages are persisted as evidence and announced on the event feed.
+package slasher
+
+import (
+	"sync"
+
+	"github.com/geanlabs/gean/chain/events"
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/types"
+)
+
+var log = logging.NewComponentLogger(logging.CompSlasher)
+
+// Slasher remembers the blocks and votes of every validator since the last
+// finalized slot and records evidence when a new one conflicts with them.
+// A nil Slasher ignores everything.
+type Slasher struct {
+	db     storage.Store
+	events *events.Feed
+
+	mu        sync.Mutex
+	proposals map[proposal][32]byte
+	// votes holds each validator's first attestation per attestation slot.
+	votes map[uint64]map[uint64]*types.SignedAttestation
+	// recorded holds the IDs of the evidence recorded per evidence slot.
+	recorded map[uint64]map[string]bool
+}
+
+type proposal struct {
+	slot     uint64
+	proposer uint64
+}
+
+// New returns a slasher that persists evidence to db and publishes it on
+// feed, which may be nil.
+func New(db storage.Store, feed *events.Feed) *Slasher {
+	return &Slasher{
+		db:        db,
+		events:    feed,
+		proposals: make(map[proposal][32]byte),
+		votes:     make(map[uint64]map[uint64]*types.SignedAttestation),
+		recorded:  make(map[uint64]map[string]bool),
+	}
+}
+
+// CheckBlock looks for another block by the same proposer at the same slot.
+// The block must have been imported, with its signatures verified, under root.
+func (s *Slasher) CheckBlock(root [32]byte, sb *types.SignedBlockWithAttestation) {
+	if s == nil {
+		return
+	}
+	block := sb.Message.Block
+	key := proposal{slot: block.Slot, proposer: block.ProposerIndex}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.proposals[key]
+	if !ok {
+		s.proposals[key] = root
+		return
+	}
+	if prev == root {
+		return
+	}
+	first, ok := s.db.GetSignedBlock(prev)
+	if !ok {
+		return
+	}
+	s.recordLocked(&storage.Evidence{
+		Kind:      storage.ProposerEquivocation,
+		Validator: block.ProposerIndex,
+		Slot:      block.Slot,
+		Blocks:    [2]*types.SignedBlockWithAttestation{first, sb},
+	})
+}
+
+// CheckAttestation looks for an earlier vote by the same validator that
+// the attestation double votes with or surrounds, or is surrounded by. Its
+// signature must already have been verified.
+func (s *Slasher) CheckAttestation(sa *types.SignedAttestation) {
+	if s == nil {
+		return
+	}
+	validator := sa.Message.ValidatorID
+	data := sa.Message.Data
+	dataRoot, _ := data.HashTreeRoot()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	votes, ok := s.votes[validator]
+	if !ok {
+		votes = make(map[uint64]*types.SignedAttestation)
+		s.votes[validator] = votes
+	}
+	if prev, ok := votes[data.Slot]; ok {
+		if prevRoot, _ := prev.Message.Data.HashTreeRoot(); prevRoot == dataRoot {
+			return // seen before
+		}
+	}
+
+	for _, prev := range votes {
+		if kind, ok := conflict(prev.Message.Data, data); ok {
+			s.recordLocked(&storage.Evidence{
+				Kind:         kind,
+				Validator:    validator,
+				Slot:         data.Slot,
+				Attestations: [2]*types.SignedAttestation{prev, sa},
+			})
+		}
+	}
+	if _, ok := votes[data.Slot]; !ok {
+		votes[data.Slot] = sa
+	}
+}
+
+// conflict reports whether two distinct votes by one validator are
+// slashable together, and how.
+func conflict(a, b *types.AttestationData) (storage.EvidenceKind, bool) {
+	switch {
+	case a.Slot == b.Slot:
+		return storage.DoubleVote, true
+	case a.Target.Slot == b.Target.Slot:
+		if a.Target.Root != b.Target.Root || *a.Source != *b.Source {
+			return storage.DoubleVote, true
+		}
+	case a.Source.Slot < b.Source.Slot && b.Target.Slot < a.Target.Slot,
+		b.Source.Slot < a.Source.Slot && a.Target.Slot < b.Target.Slot:
+		return storage.SurroundVote, true
+	}
+	return 0, false
+}
+
+// Prune forgets blocks, votes and recorded evidence for slots before
+// finalizedSlot.
+func (s *Slasher) Prune(finalizedSlot uint64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.proposals {
+		if key.slot < finalizedSlot {
+			delete(s.proposals, key)
+		}
+	}
+	for validator, votes := range s.votes {
+		for slot := range votes {
+			if slot < finalizedSlot {
+				delete(votes, slot)
+			}
+		}
+		if len(votes) == 0 {
+			delete(s.votes, validator)
+		}
+	}
+	for slot := range s.recorded {
+		if slot < finalizedSlot {
+			delete(s.recorded, slot)
+		}
+	}
+}
+
+// Evidence returns all recorded evidence, oldest first.
+func (s *Slasher) Evidence() []*storage.Evidence {
+	if s == nil {
+		return nil
+	}
+	return s.db.GetAllEvidence()
+}
+
+func (s *Slasher) recordLocked(ev *storage.Evidence) {
+	id := string(ev.ID())
+	recorded, ok := s.recorded[ev.Slot]
+	if !ok {
+		recorded = make(map[string]bool)
+		s.recorded[ev.Slot] = recorded
+	}
+	if recorded[id] {
+		return
+	}
+	recorded[id] = true
+
+	s.db.PutEvidence(ev)
+	metrics.SlashingsDetected.WithLabelValues(ev.Kind.String()).Inc()
+	s.events.Publish(events.TopicSlashing, events.SlashingEvent{
+		Kind:      ev.Kind.String(),
+		Validator: ev.Validator,
+		Slot:      ev.Slot,
+	})
+	log.Warn("validator equivocated", "kind", ev.Kind.String(), "validator", ev.Validator, "slot", ev.Slot)
+}
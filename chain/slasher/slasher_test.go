@@ -0,0 +1,133 @@
+package slasher_test
+
+import (
+	"testing"
+
+	"github.com/geanlabs/gean/chain/slasher"
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func vote(validator, slot, sourceSlot, targetSlot uint64, head byte) *types.SignedAttestation {
+	return &types.SignedAttestation{
+		Message: &types.Attestation{
+			ValidatorID: validator,
+			Data: &types.AttestationData{
+				Slot:   slot,
+				Head:   &types.Checkpoint{Root: [32]byte{head}, Slot: slot},
+				Target: &types.Checkpoint{Root: [32]byte{byte(targetSlot)}, Slot: targetSlot},
+				Source: &types.Checkpoint{Root: [32]byte{byte(sourceSlot)}, Slot: sourceSlot},
+			},
+		},
+	}
+}
+
+func signedBlock(slot, proposer uint64, parent byte) *types.SignedBlockWithAttestation {
+	return &types.SignedBlockWithAttestation{
+		Message: &types.BlockWithAttestation{
+			Block: &types.Block{
+				Slot:          slot,
+				ProposerIndex: proposer,
+				ParentRoot:    [32]byte{parent},
+				Body:          &types.BlockBody{Attestations: []*types.Attestation{}},
+			},
+			ProposerAttestation: vote(proposer, slot, 0, slot, parent).Message,
+		},
+	}
+}
+
+func TestDoubleVoteRecordsEvidence(t *testing.T) {
+	db := memory.New()
+	s := slasher.New(db, nil)
+
+	first, second := vote(2, 5, 0, 5, 1), vote(2, 5, 0, 5, 2)
+	s.CheckAttestation(first)
+	s.CheckAttestation(second)
+	s.CheckAttestation(second)
+
+	evidence := db.GetAllEvidence()
+	if len(evidence) != 1 {
+		t.Fatalf("evidence = %d, want 1", len(evidence))
+	}
+	ev := evidence[0]
+	if ev.Kind != storage.DoubleVote || ev.Validator != 2 || ev.Slot != 5 {
+		t.Fatalf("evidence = %v validator %d slot %d, want double_vote by 2 at 5", ev.Kind, ev.Validator, ev.Slot)
+	}
+	if ev.Attestations[0] != first || ev.Attestations[1] != second {
+		t.Fatal("evidence does not hold both attestations in order")
+	}
+}
+
+func TestSurroundVoteRecordsEvidence(t *testing.T) {
+	db := memory.New()
+	s := slasher.New(db, nil)
+
+	s.CheckAttestation(vote(1, 6, 2, 3, 1))
+	s.CheckAttestation(vote(1, 8, 1, 6, 1))
+
+	evidence := db.GetAllEvidence()
+	if len(evidence) != 1 || evidence[0].Kind != storage.SurroundVote {
+		t.Fatalf("evidence = %v, want one surround_vote", evidence)
+	}
+}
+
+func TestConsistentVotesRecordNothing(t *testing.T) {
+	db := memory.New()
+	s := slasher.New(db, nil)
+
+	// Repeats, and votes that move source and target forward, are fine.
+	s.CheckAttestation(vote(0, 2, 0, 2, 1))
+	s.CheckAttestation(vote(0, 2, 0, 2, 1))
+	s.CheckAttestation(vote(0, 3, 2, 3, 1))
+	s.CheckAttestation(vote(0, 4, 2, 4, 1))
+	// The same vote by another validator is not an offence either.
+	s.CheckAttestation(vote(1, 2, 0, 2, 2))
+
+	if evidence := s.Evidence(); len(evidence) != 0 {
+		t.Fatalf("evidence = %d, want 0", len(evidence))
+	}
+}
+
+func TestProposerEquivocationRecordsEvidence(t *testing.T) {
+	db := memory.New()
+	s := slasher.New(db, nil)
+
+	a, b := signedBlock(4, 1, 1), signedBlock(4, 1, 2)
+	rootA, _ := a.Message.Block.HashTreeRoot()
+	rootB, _ := b.Message.Block.HashTreeRoot()
+	db.PutSignedBlock(rootA, a)
+	db.PutSignedBlock(rootB, b)
+
+	s.CheckBlock(rootA, a)
+	s.CheckBlock(rootA, a)
+	if evidence := db.GetAllEvidence(); len(evidence) != 0 {
+		t.Fatalf("evidence = %d after one block, want 0", len(evidence))
+	}
+	s.CheckBlock(rootB, b)
+
+	evidence := db.GetAllEvidence()
+	if len(evidence) != 1 {
+		t.Fatalf("evidence = %d, want 1", len(evidence))
+	}
+	ev := evidence[0]
+	if ev.Kind != storage.ProposerEquivocation || ev.Validator != 1 || ev.Slot != 4 {
+		t.Fatalf("evidence = %v validator %d slot %d, want proposer_equivocation by 1 at 4", ev.Kind, ev.Validator, ev.Slot)
+	}
+	if ev.Blocks[0].Message.Block.ParentRoot != a.Message.Block.ParentRoot || ev.Blocks[1] != b {
+		t.Fatal("evidence does not hold both blocks in order")
+	}
+}
+
+func TestPruneForgetsFinalizedVotes(t *testing.T) {
+	db := memory.New()
+	s := slasher.New(db, nil)
+
+	s.CheckAttestation(vote(0, 3, 0, 3, 1))
+	s.Prune(4)
+	s.CheckAttestation(vote(0, 3, 0, 3, 2))
+
+	if evidence := s.Evidence(); len(evidence) != 0 {
+		t.Fatalf("evidence = %d, want 0 after pruning", len(evidence))
+	}
+}
@@ -0,0 +1,198 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+// errInvalidBatch marks a range response that the serving peer is to blame for.
+var errInvalidBatch = errors.New("invalid batch")
+
+type batchState int
+
+const (
+	batchPending batchState = iota
+	batchDownloading
+	batchDownloaded
+)
+
+// batch is a range of slots downloaded from one peer with blocks_by_range.
+type batch struct {
+	start, count uint64
+	state        batchState
+	// peer is serving, or served, the current download.
+	peer peer.ID
+	// failed holds the peers a download or import of the batch failed with.
+	failed map[peer.ID]bool
+	// empty holds the peers that returned no blocks for the batch.
+	empty map[peer.ID]bool
+
+	blocks []*types.SignedBlockWithAttestation
+	err    error
+}
+
+// download fetches slots [start, end] in batches, each from an idle peer
+// that has not failed it before, with up to maxBatchesAhead batches in
+// flight, and imports them in slot order as they arrive. A batch that
+// fails, to download or to import, is re-requested from another peer. It
+// returns the number of blocks imported, stopping early when a batch has
+// failed with every peer. Slots may be genuinely empty, so an empty batch is
+// only accepted once every peer has returned it empty or failed it.
+func (s *Syncer) download(ctx context.Context, start, end uint64, peers []peer.ID) int {
+	size := s.BatchSize
+	if size == 0 {
+		size = DefaultBatchSize
+	}
+	var batches []*batch
+	for slot := start; slot <= end; slot += size {
+		batches = append(batches, &batch{start: slot, count: min(size, end-slot+1), failed: make(map[peer.ID]bool), empty: make(map[peer.ID]bool)})
+	}
+
+	// Buffered so that downloads still in flight when we give up can finish.
+	results := make(chan *batch, len(batches))
+	busy := make(map[peer.ID]bool)
+	imported, next, inFlight := 0, 0, 0
+	for next < len(batches) {
+		for i := next; i < min(next+maxBatchesAhead, len(batches)); i++ {
+			b := batches[i]
+			if b.state != batchPending {
+				continue
+			}
+			pid, ok := pickPeer(peers, i, busy, b.failed)
+			if !ok {
+				continue
+			}
+			b.state, b.peer = batchDownloading, pid
+			busy[pid] = true
+			inFlight++
+			go func() {
+				b.blocks, b.err = reqresp.RequestBlocksByRange(ctx, s.Host, pid, b.start, b.count)
+				results <- b
+			}()
+		}
+		if inFlight == 0 {
+			log.Warn("range sync stalled: no peer could serve batch",
+				"start_slot", batches[next].start,
+				"count", batches[next].count,
+			)
+			return imported
+		}
+
+		var b *batch
+		select {
+		case <-ctx.Done():
+			return imported
+		case b = <-results:
+		}
+		inFlight--
+		delete(busy, b.peer)
+		if b.err != nil {
+			s.failBatch(b, b.err)
+			continue
+		}
+		if len(b.blocks) == 0 && b.retryEmpty(peers) {
+			continue
+		}
+		metrics.SyncBatches.WithLabelValues("success").Inc()
+		b.state = batchDownloaded
+
+		// Import downloaded batches in slot order.
+		for next < len(batches) && batches[next].state == batchDownloaded {
+			b := batches[next]
+			n, err := s.importBatch(b)
+			imported += n
+			if err != nil {
+				if errors.Is(err, errInvalidBatch) {
+					s.penalize(b.peer, err)
+				}
+				s.failBatch(b, err)
+				break
+			}
+			if n > 0 {
+				for pid := range b.empty {
+					s.penalize(pid, fmt.Errorf("%w: no blocks for slots %d..%d that %s served",
+						errInvalidBatch, b.start, b.start+b.count-1, b.peer))
+				}
+			}
+			b.blocks = nil
+			next++
+		}
+	}
+	return imported
+}
+
+// importBatch processes a batch's blocks in order and returns how many were
+// imported before the first error. Each block must lie in the batch's range
+// and build on the block before it.
+func (s *Syncer) importBatch(b *batch) (int, error) {
+	var prev [32]byte
+	for i, sb := range b.blocks {
+		block := sb.Message.Block
+		if block.Slot < b.start || block.Slot >= b.start+b.count {
+			return i, fmt.Errorf("%w: block slot %d outside requested range", errInvalidBatch, block.Slot)
+		}
+		if i > 0 && block.ParentRoot != prev {
+			return i, fmt.Errorf("%w: block at slot %d skips its parent %x", errInvalidBatch, block.Slot, block.ParentRoot[:4])
+		}
+		if err := s.FC.ProcessBlock(sb); err != nil {
+			return i, fmt.Errorf("slot %d: %w", block.Slot, err)
+		}
+		prev, _ = block.HashTreeRoot()
+		if s.OnImport != nil {
+			s.OnImport(prev)
+		}
+	}
+	log.Debug("range sync batch imported", "start_slot", b.start, "count", b.count, "blocks", len(b.blocks))
+	return len(b.blocks), nil
+}
+
+// failBatch marks a batch's download as failed with its peer, so it is
+// re-requested from another.
+func (s *Syncer) failBatch(b *batch, err error) {
+	metrics.SyncBatches.WithLabelValues("failure").Inc()
+	log.Debug("range sync batch failed", "peer_id", b.peer.String(), "start_slot", b.start, "err", err)
+	b.failed[b.peer] = true
+	b.state, b.blocks, b.err = batchPending, nil, nil
+}
+
+// retryEmpty records that the batch came back empty from its peer. It
+// re-queues the batch and returns true if a peer remains that has not
+// returned or failed it yet.
+func (b *batch) retryEmpty(peers []peer.ID) bool {
+	b.empty[b.peer] = true
+	b.failed[b.peer] = true
+	for _, pid := range peers {
+		if !b.failed[pid] {
+			b.state = batchPending
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Syncer) penalize(pid peer.ID, err error) {
+	log.Debug("range sync peer penalized", "peer_id", pid.String(), "err", err)
+	if s.Penalize != nil {
+		s.Penalize(pid)
+	}
+}
+
+// pickPeer returns a peer that is neither busy nor has failed the batch,
+// starting from a different peer for each batch index so that consecutive
+// batches go to different peers.
+func pickPeer(peers []peer.ID, index int, busy, failed map[peer.ID]bool) (peer.ID, bool) {
+	for j := range peers {
+		pid := peers[(index+j)%len(peers)]
+		if !busy[pid] && !failed[pid] {
+			return pid, true
+		}
+	}
+	return "", false
+}
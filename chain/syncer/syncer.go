@@ -0,0 +1,315 @@
+// Package syncer keeps the chain caught up with its peers. It tracks the
+// status of every peer, picks a sync target that peers agree on, and
+// downloads the missing range in batches from several peers at once.
+package syncer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+var log = logging.NewComponentLogger(logging.CompSync)
+
+// State is the sync state of the node.
+type State int
+
+const (
+	// StateIdle means no peer status is known, so there is nothing to sync from.
+	StateIdle State = iota
+	// StateSyncing means a peer target is ahead and blocks are being downloaded.
+	StateSyncing
+	// StateSynced means no peer target is meaningfully ahead of our head.
+	StateSynced
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateSyncing:
+		return "syncing"
+	case StateSynced:
+		return "synced"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// DefaultBatchSize is the number of slots requested per blocks_by_range call.
+	DefaultBatchSize = 64
+	// maxBatchesAhead bounds how far downloads may run ahead of import, in
+	// batches, and so how many blocks are held in memory.
+	maxBatchesAhead = 8
+	// syncTolerance is how many slots a target may be ahead of our head
+	// before range sync starts; smaller gaps are closed by gossip.
+	syncTolerance = 2
+	// syncInterval is how often the sync target is re-evaluated.
+	syncInterval = types.SecondsPerSlot * time.Second
+	// statusRefreshInterval is how old a peer's status may get before it
+	// is exchanged again. While our head lags the wall clock, statuses older
+	// than a slot are refreshed.
+	statusRefreshInterval = 10 * syncInterval
+)
+
+// Syncer tracks peer statuses and range syncs towards the best target.
+type Syncer struct {
+	Host host.Host
+	FC   *forkchoice.Store
+	// Handshake, if set, re-exchanges status with peers whose status is stale.
+	Handshake *reqresp.Handshake
+	// OnImport, if set, is called with the root of every block range sync
+	// imports, e.g. to replay blocks that were waiting for it.
+	OnImport func(root [32]byte)
+	// Penalize, if set, lowers the score of a peer that served an invalid
+	// range: blocks outside it, blocks that skip a block of the chain, or
+	// no blocks where another peer had some.
+	Penalize func(peer.ID)
+	// CurrentSlot, if set, returns the wall-clock slot, so that statuses are
+	// refreshed sooner while our head lags it.
+	CurrentSlot func() uint64
+	// BatchSize defaults to DefaultBatchSize.
+	BatchSize uint64
+
+	mu    sync.Mutex
+	peers map[peer.ID]*peerStatus
+	state State
+	wake  chan struct{}
+}
+
+type peerStatus struct {
+	status  reqresp.Status
+	updated time.Time
+}
+
+// Start tracks peer disconnections and runs sync rounds until ctx is
+// cancelled. Statuses of peers connected before Start are exchanged on the
+// first round.
+func (s *Syncer) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.peers == nil {
+		s.peers = make(map[peer.ID]*peerStatus)
+	}
+	s.wake = make(chan struct{}, 1)
+	s.mu.Unlock()
+	metrics.SyncState.Set(float64(s.State()))
+
+	s.Host.Network().Notify(&network.NotifyBundle{
+		DisconnectedF: func(n network.Network, c network.Conn) {
+			if n.Connectedness(c.RemotePeer()) != network.Connected {
+				s.removePeer(c.RemotePeer())
+			}
+		},
+	})
+
+	go func() {
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+		for {
+			s.round(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+// SetPeerStatus records a peer's status, e.g. after a handshake, and
+// triggers a sync round.
+func (s *Syncer) SetPeerStatus(pid peer.ID, status reqresp.Status) {
+	s.mu.Lock()
+	if s.peers == nil {
+		s.peers = make(map[peer.ID]*peerStatus)
+	}
+	s.peers[pid] = &peerStatus{status: status, updated: time.Now()}
+	wake := s.wake
+	s.mu.Unlock()
+
+	if wake != nil {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// State returns the current sync state.
+func (s *Syncer) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Target returns what to sync towards: the finalized checkpoint that the
+// most peers report, or failing that the head that the most peers report,
+// with ties going to the higher slot. Only checkpoints more than
+// syncTolerance slots ahead of our head are considered. It also returns
+// the peers that report the target, or nil if no peer is that far ahead.
+func (s *Syncer) Target() (*types.Checkpoint, []peer.ID) {
+	minSlot := s.FC.GetStatus().HeadSlot + syncTolerance + 1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if target, peers := s.bestLocked(minSlot, func(st *reqresp.Status) *types.Checkpoint { return st.Finalized }); target != nil {
+		return target, peers
+	}
+	return s.bestLocked(minSlot, func(st *reqresp.Status) *types.Checkpoint { return st.Head })
+}
+
+// bestLocked groups peers by the checkpoint cp picks from their status and
+// returns the most reported checkpoint at or above minSlot, and its peers.
+func (s *Syncer) bestLocked(minSlot uint64, cp func(*reqresp.Status) *types.Checkpoint) (*types.Checkpoint, []peer.ID) {
+	votes := make(map[types.Checkpoint][]peer.ID)
+	for pid, ps := range s.peers {
+		if c := cp(&ps.status); c != nil && c.Slot >= minSlot {
+			votes[*c] = append(votes[*c], pid)
+		}
+	}
+
+	var best *types.Checkpoint
+	var bestPeers []peer.ID
+	for c, peers := range votes {
+		if best == nil || len(peers) > len(bestPeers) || (len(peers) == len(bestPeers) && c.Slot > best.Slot) {
+			best, bestPeers = &c, peers
+		}
+	}
+	return best, bestPeers
+}
+
+func (s *Syncer) removePeer(pid peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.peers, pid)
+}
+
+func (s *Syncer) setState(state State) {
+	s.mu.Lock()
+	old := s.state
+	s.state = state
+	s.mu.Unlock()
+
+	metrics.SyncState.Set(float64(state))
+	if state != old {
+		log.Info("sync state changed", "from", old.String(), "to", state.String())
+	}
+}
+
+// round refreshes stale peer statuses, picks a target and downloads up to it.
+func (s *Syncer) round(ctx context.Context) {
+	s.refreshStatuses(ctx)
+
+	target, peers := s.Target()
+	if target == nil {
+		s.mu.Lock()
+		idle := len(s.peers) == 0
+		s.mu.Unlock()
+		if idle {
+			s.setState(StateIdle)
+		} else {
+			s.setState(StateSynced)
+		}
+		return
+	}
+
+	s.setState(StateSyncing)
+	// Our head may be on a fork the peers do not share, so download from
+	// the latest block we have in common with them.
+	start := s.commonSlot(ctx, peers[0]) + 1
+	log.Info("range sync started",
+		"start_slot", start,
+		"target_slot", target.Slot,
+		"target_root", logging.ShortHash(target.Root),
+		"peers", len(peers),
+	)
+	imported := s.download(ctx, start, target.Slot, peers)
+	log.Info("range sync finished", "imported", imported, "head_slot", s.FC.GetStatus().HeadSlot)
+	if imported > 0 {
+		// Progress was made; re-evaluate straight away.
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// commonSlot returns the slot of the latest block on our canonical chain
+// that pid holds at the same slot, probing back from our head at doubling
+// distances. It falls back to our finalized slot, which every peer we sync
+// from shares, when no later common block is found.
+func (s *Syncer) commonSlot(ctx context.Context, pid peer.ID) uint64 {
+	status := s.FC.GetStatus()
+	root, hops, probe := status.Head, 0, 0
+	for {
+		block, ok := s.FC.GetBlock(root)
+		if !ok || block.Slot <= status.FinalizedSlot {
+			return status.FinalizedSlot
+		}
+		if hops == probe {
+			blocks, err := reqresp.RequestBlocksByRange(ctx, s.Host, pid, block.Slot, 1)
+			if err != nil {
+				return status.FinalizedSlot
+			}
+			if len(blocks) == 1 {
+				if theirs, _ := blocks[0].Message.Block.HashTreeRoot(); theirs == root {
+					return block.Slot
+				}
+			}
+			probe = 2*probe + 1
+		}
+		hops++
+		root = block.ParentRoot
+	}
+}
+
+// refreshStatuses exchanges status again, concurrently, with connected
+// peers whose status is stale or unknown.
+func (s *Syncer) refreshStatuses(ctx context.Context) {
+	if s.Handshake == nil {
+		return
+	}
+	maxAge := statusRefreshInterval
+	if s.CurrentSlot != nil && s.CurrentSlot() > s.FC.GetStatus().HeadSlot+syncTolerance {
+		maxAge = syncInterval
+	}
+
+	var stale []peer.ID
+	s.mu.Lock()
+	for _, pid := range s.Host.Network().Peers() {
+		if ps, ok := s.peers[pid]; !ok || time.Since(ps.updated) >= maxAge {
+			stale = append(stale, pid)
+		}
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, pid := range stale {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := s.Handshake.Run(ctx, pid)
+			if err != nil {
+				log.Debug("status refresh failed", "peer_id", pid.String(), "err", err)
+				s.removePeer(pid)
+				return
+			}
+			s.mu.Lock()
+			s.peers[pid] = &peerStatus{status: *status, updated: time.Now()}
+			s.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,173 @@
+package syncer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/syncer"
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/storage/memory"
+	"github.com/geanlabs/gean/types"
+)
+
+func status(finalized, head types.Checkpoint) reqresp.Status {
+	return reqresp.Status{Finalized: &finalized, Head: &head}
+}
+
+func TestTargetPrefersFinalizedCheckpointMostPeersReport(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	s := &syncer.Syncer{FC: forkchoice.NewStore(state, genesis, memory.New())}
+
+	common := types.Checkpoint{Root: [32]byte{1}, Slot: 10}
+	s.SetPeerStatus("a", status(common, types.Checkpoint{Root: [32]byte{2}, Slot: 12}))
+	s.SetPeerStatus("b", status(common, types.Checkpoint{Root: [32]byte{3}, Slot: 14}))
+	s.SetPeerStatus("c", status(types.Checkpoint{Root: [32]byte{4}, Slot: 20}, types.Checkpoint{Root: [32]byte{4}, Slot: 20}))
+
+	target, peers := s.Target()
+	if target == nil || *target != common {
+		t.Fatalf("target = %+v, want %+v", target, common)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("target peers = %v, want a and b", peers)
+	}
+}
+
+func TestTargetFallsBackToHeadAndIgnoresNearbyPeers(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	s := &syncer.Syncer{FC: forkchoice.NewStore(state, genesis, memory.New())}
+
+	var none types.Checkpoint
+	s.SetPeerStatus("a", status(none, types.Checkpoint{Root: [32]byte{1}, Slot: 2}))
+	if target, _ := s.Target(); target != nil {
+		t.Fatalf("target = %+v, want none within sync tolerance", target)
+	}
+
+	head := types.Checkpoint{Root: [32]byte{2}, Slot: 8}
+	s.SetPeerStatus("b", status(none, head))
+	s.SetPeerStatus("c", status(none, head))
+	s.SetPeerStatus("d", status(none, types.Checkpoint{Root: [32]byte{3}, Slot: 9}))
+	target, peers := s.Target()
+	if target == nil || *target != head || len(peers) != 2 {
+		t.Fatalf("target = %+v from %v, want %+v from b and c", target, peers, head)
+	}
+}
+
+func TestSyncRetriesFailedBatchesOnOtherPeers(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	const headSlot = 9
+	var head *types.SignedBlockWithAttestation
+	for slot := uint64(1); slot <= headSlot; slot++ {
+		var err error
+		if head, err = producer.ProduceBlock(slot, slot%3, testutil.Signer{}); err != nil {
+			t.Fatalf("ProduceBlock(%d): %v", slot, err)
+		}
+	}
+	producer.AcceptNewAttestations()
+	headRoot, _ := head.Message.Block.HashTreeRoot()
+	genesisRoot, _ := genesis.HashTreeRoot()
+
+	// good serves the chain; bad answers every blocks_by_range request
+	// with no blocks.
+	local, good, bad := testutil.NewHost(t), testutil.NewHost(t), testutil.NewHost(t)
+	reqresp.RegisterReqResp(good, &reqresp.ReqRespHandler{
+		OnBlocksByRange: func(req reqresp.BlocksByRangeRequest) []*types.SignedBlockWithAttestation {
+			return producer.GetCanonicalBlocks(req.StartSlot, req.Count)
+		},
+	})
+	reqresp.RegisterReqResp(bad, &reqresp.ReqRespHandler{
+		OnBlocksByRange: func(reqresp.BlocksByRangeRequest) []*types.SignedBlockWithAttestation { return nil },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, h := range []host.Host{good, bad} {
+		if err := local.Connect(ctx, peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}); err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+	}
+
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	penalized := make(chan peer.ID, 16)
+	s := &syncer.Syncer{Host: local, FC: fc, BatchSize: 2, Penalize: func(pid peer.ID) { penalized <- pid }}
+	if got := s.State(); got != syncer.StateIdle {
+		t.Fatalf("state = %v, want idle", got)
+	}
+	peerStatus := status(types.Checkpoint{Root: genesisRoot}, types.Checkpoint{Root: headRoot, Slot: headSlot})
+	s.SetPeerStatus(good.ID(), peerStatus)
+	s.SetPeerStatus(bad.ID(), peerStatus)
+	s.Start(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for fc.GetStatus().HeadSlot != headSlot || s.State() != syncer.StateSynced {
+		if time.Now().After(deadline) {
+			t.Fatalf("head slot = %d, state = %v, want %d and synced", fc.GetStatus().HeadSlot, s.State(), headSlot)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if pid := <-penalized; pid != bad.ID() {
+		t.Fatalf("penalized %s, want the peer that returned empty batches", pid)
+	}
+}
+
+func TestSyncResumesFromCommonBlock(t *testing.T) {
+	state, genesis := testutil.Genesis(3)
+	producer := forkchoice.NewStore(state, genesis, memory.New())
+	fc := forkchoice.NewStore(state, genesis, memory.New())
+	const commonSlot, headSlot = 5, 9
+	var head *types.SignedBlockWithAttestation
+	for slot := uint64(1); slot <= headSlot; slot++ {
+		var err error
+		if head, err = producer.ProduceBlock(slot, slot%3, testutil.Signer{}); err != nil {
+			t.Fatalf("ProduceBlock(%d): %v", slot, err)
+		}
+		if slot <= commonSlot {
+			if err := fc.ProcessBlock(head); err != nil {
+				t.Fatalf("ProcessBlock(%d): %v", slot, err)
+			}
+		}
+	}
+	producer.AcceptNewAttestations()
+	fc.AcceptNewAttestations()
+	headRoot, _ := head.Message.Block.HashTreeRoot()
+	genesisRoot, _ := genesis.HashTreeRoot()
+
+	// Requests for more than one slot are range downloads rather than
+	// common-block probes.
+	local, remote := testutil.NewHost(t), testutil.NewHost(t)
+	starts := make(chan uint64, 16)
+	reqresp.RegisterReqResp(remote, &reqresp.ReqRespHandler{
+		OnBlocksByRange: func(req reqresp.BlocksByRangeRequest) []*types.SignedBlockWithAttestation {
+			if req.Count > 1 {
+				starts <- req.StartSlot
+			}
+			return producer.GetCanonicalBlocks(req.StartSlot, req.Count)
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := local.Connect(ctx, peer.AddrInfo{ID: remote.ID(), Addrs: remote.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	s := &syncer.Syncer{Host: local, FC: fc}
+	s.SetPeerStatus(remote.ID(), status(types.Checkpoint{Root: genesisRoot}, types.Checkpoint{Root: headRoot, Slot: headSlot}))
+	s.Start(ctx)
+
+	if start := <-starts; start != commonSlot+1 {
+		t.Fatalf("range sync started at slot %d, want %d", start, commonSlot+1)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for fc.GetStatus().HeadSlot != headSlot {
+		if time.Now().After(deadline) {
+			t.Fatalf("head slot = %d, want %d", fc.GetStatus().HeadSlot, headSlot)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
@@ -15,6 +17,8 @@ import (
 	"github.com/geanlabs/gean/config"
 	"github.com/geanlabs/gean/node"
 	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/slashprotection"
+	"github.com/geanlabs/gean/types"
 )
 
 func main() {
@@ -26,9 +30,19 @@ func main() {
 	validatorKeys := flag.String("validator-keys", "", "Path to directory containing validator keys")
 	listenAddr := flag.String("listen-addr", "/ip4/0.0.0.0/udp/9000/quic-v1", "QUIC listen address")
 	metricsPort := flag.Int("metrics-port", 8080, "Prometheus metrics port (0 = disabled)")
+	apiPort := flag.Int("api-port", 0, "REST API port (0 = disabled)")
+	targetPeers := flag.Int("target-peers", 8, "Number of peers to dial up to")
+	maxPeers := flag.Int("max-peers", 16, "Maximum number of connected peers")
 	discoveryPort := flag.Int("discovery-port", 9000, "Discovery v5 UDP port")
 	dataDir := flag.String("data-dir", ".", "Data directory for node database and keys")
-	devnetID := flag.String("devnet-id", "devnet0", "Devnet identifier for gossip topics")
+	dbBackend := flag.String("db", "memory", "Storage backend (memory, leveldb); leveldb persists the chain under <data-dir>/db")
+	checkpointSyncURL := flag.String("checkpoint-sync-url", "", "URL of a trusted gean node to fetch the finalized state and anchor block from")
+	checkpointState := flag.String("checkpoint-state", "", "Path to an SSZ finalized state to start from instead of genesis")
+	checkpointBlock := flag.String("checkpoint-block", "", "Path to the SSZ signed anchor block of --checkpoint-state")
+	backfillVerify := flag.Bool("backfill-verify-signatures", false, "Verify proposer signatures of historical blocks backfilled behind a checkpoint")
+	spImport := flag.String("slashing-protection-import", "", "Import an interchange JSON file into the slashing-protection database before starting")
+	spExport := flag.String("slashing-protection-export", "", "Export the slashing-protection database to an interchange JSON file and exit")
+	devnetID := flag.String("devnet-id", "", "Network name in gossip topics (default: the fork digest)")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	flag.Parse()
 
@@ -61,6 +75,27 @@ func main() {
 		logger.Warn("genesis time is in the past", "genesis_time", genCfg.GenesisTime, "now", time.Now().Unix())
 	}
 
+	if (*checkpointState == "") != (*checkpointBlock == "") {
+		logger.Error("--checkpoint-state and --checkpoint-block must be set together")
+		os.Exit(1)
+	}
+
+	if *spExport != "" {
+		if err := exportSlashingProtection(*dataDir, *spExport, genCfg.Validators); err != nil {
+			logger.Error("failed to export slashing protection", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("slashing protection exported", "file", *spExport)
+		return
+	}
+	if *spImport != "" {
+		if err := importSlashingProtection(*dataDir, *spImport, genCfg.Validators); err != nil {
+			logger.Error("failed to import slashing protection", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("slashing protection imported", "file", *spImport)
+	}
+
 	// Load bootnodes.
 	var bootnodes []string
 	if *bootnodesPath != "" {
@@ -106,9 +141,19 @@ func main() {
 		ValidatorIDs:     validatorIDs,
 		ValidatorKeysDir: *validatorKeys,
 		MetricsPort:      *metricsPort,
+		APIPort:          *apiPort,
 		DiscoveryPort:    *discoveryPort,
+		TargetPeers:      *targetPeers,
+		MaxPeers:         *maxPeers,
 		DataDir:          *dataDir,
 		DevnetID:         *devnetID,
+		DB:               *dbBackend,
+
+		CheckpointSyncURL:   *checkpointSyncURL,
+		CheckpointStatePath: *checkpointState,
+		CheckpointBlockPath: *checkpointBlock,
+
+		BackfillVerifySignatures: *backfillVerify,
 	}
 
 	n, err := node.New(nodeCfg)
@@ -134,6 +179,40 @@ func main() {
 	}
 }
 
+func exportSlashingProtection(dataDir, path string, validators []*types.Validator) error {
+	db, err := slashprotection.Open(node.SlashingProtectionPath(dataDir))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ic, err := db.Export(validators)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ic, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func importSlashingProtection(dataDir, path string, validators []*types.Validator) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var ic slashprotection.Interchange
+	if err := json.Unmarshal(data, &ic); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	db, err := slashprotection.Open(node.SlashingProtectionPath(dataDir))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Import(&ic, validators)
+}
+
 func parseLevel(s string) slog.Level {
 	switch s {
 	case "debug":
@@ -13,6 +13,8 @@ import (
 func main() {
 	count := flag.Int("validators", 5, "Number of keys to generate")
 	outDir := flag.String("keys-dir", "keys", "Output directory for keys")
+	activationEpoch := flag.Uint64("activation-epoch", 0, "First epoch (slot) the keys can sign")
+	activeEpochs := flag.Uint64("active-epochs", 256, "Number of epochs (slots) the keys can sign for")
 	printYAML := flag.Bool("print-yaml", false, "Print GENESIS_VALIDATORS yaml to stdout")
 	flag.Parse()
 
@@ -27,8 +29,7 @@ func main() {
 	for i := 0; i < *count; i++ {
 		// Deterministic seed based on index
 		seed := uint64(i)
-		// Activation epoch 0, active for 256 epochs
-		kp, err := leansig.GenerateKeypair(seed, 0, 256)
+		kp, err := leansig.GenerateKeypair(seed, *activationEpoch, *activeEpochs)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to generate keypair %d: %v\n", i, err)
 			os.Exit(1)
@@ -0,0 +1,47 @@
+// Package testutil holds fixtures shared by the tests of several packages.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+
+	"github.com/geanlabs/gean/chain/statetransition"
+	"github.com/geanlabs/gean/types"
+)
+
+// Genesis returns a genesis state with numValidators validators, at genesis
+// time 1000, and its anchor block.
+func Genesis(numValidators uint64) (*types.State, *types.Block) {
+	validators := make([]*types.Validator, numValidators)
+	for i := range validators {
+		validators[i] = &types.Validator{Index: uint64(i)}
+	}
+	state := statetransition.GenerateGenesis(1000, validators)
+	block := &types.Block{
+		Body: &types.BlockBody{Attestations: []*types.Attestation{}},
+	}
+	block.StateRoot, _ = state.HashTreeRoot()
+	return state, block
+}
+
+// Signer signs everything with an all-zero signature, which the signature
+// verifier stub accepts.
+type Signer struct{}
+
+func (Signer) Sign(uint32, [32]byte) ([]byte, error) {
+	return make([]byte, 3112), nil
+}
+
+// NewHost returns a libp2p host listening on a random local TCP port. It is
+// closed when the test ends.
+func NewHost(t testing.TB) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("libp2p.New: %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
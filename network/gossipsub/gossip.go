@@ -23,8 +23,9 @@ type Topics struct {
 	AggregateAttestation *pubsub.Topic
 }
 
-// NewGossipSub creates a configured gossipsub instance.
-func NewGossipSub(ctx context.Context, h host.Host) (*pubsub.PubSub, error) {
+// NewGossipSub creates a configured gossipsub instance with peer scoring.
+// The latest scores are kept in scores.
+func NewGossipSub(ctx context.Context, h host.Host, scores *PeerScores) (*pubsub.PubSub, error) {
 	return pubsub.NewGossipSub(ctx, h,
 		pubsub.WithMessageSignaturePolicy(pubsub.StrictNoSign),
 		pubsub.WithGossipSubParams(pubsub.GossipSubParams{
@@ -53,6 +54,8 @@ func NewGossipSub(ctx context.Context, h host.Host) (*pubsub.PubSub, error) {
 		}),
 		pubsub.WithSeenMessagesTTL(24*time.Second),
 		pubsub.WithMessageIdFn(ComputeMessageID),
+		pubsub.WithPeerScore(peerScoreParams(scores), PeerScoreThresholds),
+		pubsub.WithPeerScoreInspect(scores.update, slotDuration),
 	)
 }
 
@@ -3,20 +3,22 @@ package gossipsub
 import (
 	"context"
 
-	"github.com/golang/snappy"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/geanlabs/gean/types"
 )
 
 // GossipHandler processes decoded gossip messages.
 type GossipHandler struct {
-	OnBlock                 func(*types.SignedBlockWithAttestation)
+	OnBlock                 func(*types.SignedBlockWithAttestation, peer.ID)
 	OnAttestation           func(*types.SignedAttestation)
 	OnAggregatedAttestation func(*types.AggregatedAttestation)
 }
 
 // SubscribeTopics subscribes to topics and dispatches messages to handler.
+// Messages are decoded by the topic validators installed by
+// RegisterValidators, which must be registered first.
 func SubscribeTopics(ctx context.Context, topics *Topics, handler *GossipHandler) error {
 	blockSub, err := topics.Block.Subscribe()
 	if err != nil {
@@ -45,16 +47,12 @@ func readBlockMessages(ctx context.Context, sub *pubsub.Subscription, handler *G
 		if err != nil {
 			return
 		}
-		decoded, err := snappy.Decode(nil, msg.Data)
-		if err != nil {
-			continue
-		}
-		block := new(types.SignedBlockWithAttestation)
-		if err := block.UnmarshalSSZ(decoded); err != nil {
+		block, ok := msg.ValidatorData.(*types.SignedBlockWithAttestation)
+		if !ok {
 			continue
 		}
 		if handler.OnBlock != nil {
-			handler.OnBlock(block)
+			handler.OnBlock(block, msg.ReceivedFrom)
 		}
 	}
 }
@@ -65,12 +63,8 @@ func readAttestationMessages(ctx context.Context, sub *pubsub.Subscription, hand
 		if err != nil {
 			return
 		}
-		decoded, err := snappy.Decode(nil, msg.Data)
-		if err != nil {
-			continue
-		}
-		att := new(types.SignedAttestation)
-		if err := att.UnmarshalSSZ(decoded); err != nil {
+		att, ok := msg.ValidatorData.(*types.SignedAttestation)
+		if !ok {
 			continue
 		}
 		if handler.OnAttestation != nil {
@@ -85,12 +79,8 @@ func readAggregatedAttestationMessages(ctx context.Context, sub *pubsub.Subscrip
 		if err != nil {
 			return
 		}
-		decoded, err := snappy.Decode(nil, msg.Data)
-		if err != nil {
-			continue
-		}
-		agg, err := DecodeAggregatedAttestation(decoded)
-		if err != nil {
+		agg, ok := msg.ValidatorData.(*types.AggregatedAttestation)
+		if !ok {
 			continue
 		}
 		if handler.OnAggregatedAttestation != nil {
@@ -0,0 +1,186 @@
+package gossipsub
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+// Peer scoring follows the shape of the Ethereum consensus-layer gossipsub
+// scoring: peers earn score for delivering messages first and for staying in
+// the mesh, and lose it for invalid messages (topic validators returning
+// Reject), for under-delivering in the mesh and for protocol misbehaviour.
+// All counters decay once per slot.
+
+const slotDuration = types.SecondsPerSlot * time.Second
+
+// Score thresholds. Below GossipThreshold a peer gets no gossip, below
+// PublishThreshold it gets no published messages, and below
+// GraylistThreshold everything it sends is ignored. Peers that stay
+// graylisted are disconnected by the peer manager.
+var PeerScoreThresholds = &pubsub.PeerScoreThresholds{
+	GossipThreshold:             -4000,
+	PublishThreshold:            -8000,
+	GraylistThreshold:           -16000,
+	AcceptPXThreshold:           100,
+	OpportunisticGraftThreshold: 5,
+}
+
+// maxPositiveScore caps what a peer can earn across all topics, so a long
+// history of good behaviour cannot offset a burst of invalid messages.
+const maxPositiveScore = 100
+
+// peerScoreParams returns the global score parameters. Topic parameters are
+// added per topic by Topics.SetScoreParams once the topics are joined.
+func peerScoreParams(scores *PeerScores) *pubsub.PeerScoreParams {
+	return &pubsub.PeerScoreParams{
+		Topics:                      make(map[string]*pubsub.TopicScoreParams),
+		TopicScoreCap:               maxPositiveScore,
+		AppSpecificScore:            scores.appSpecificScore,
+		AppSpecificWeight:           1,
+		IPColocationFactorWeight:    -maxPositiveScore,
+		IPColocationFactorThreshold: 10,
+		BehaviourPenaltyWeight:      -16,
+		BehaviourPenaltyThreshold:   6,
+		BehaviourPenaltyDecay:       pubsub.ScoreParameterDecayWithBase(10*slotDuration, slotDuration, 0.01),
+		DecayInterval:               slotDuration,
+		DecayToZero:                 0.01,
+		RetainScore:                 100 * slotDuration,
+	}
+}
+
+// topicScoreParams returns score parameters for a topic carrying about
+// perSlot messages per slot. weight is the topic's share of the total score.
+func topicScoreParams(weight, perSlot float64) *pubsub.TopicScoreParams {
+	decay := func(slots int) float64 {
+		return pubsub.ScoreParameterDecayWithBase(time.Duration(slots)*slotDuration, slotDuration, 0.01)
+	}
+	// A mesh peer should deliver at least a tenth of the topic's traffic; a
+	// counter decaying over 5 slots settles around 5 slots' worth.
+	meshThreshold := math.Max(1, perSlot*5/10)
+	return &pubsub.TopicScoreParams{
+		TopicWeight: weight,
+
+		TimeInMeshWeight:  0.03,
+		TimeInMeshQuantum: slotDuration,
+		TimeInMeshCap:     300,
+
+		FirstMessageDeliveriesWeight: 1,
+		FirstMessageDeliveriesDecay:  decay(20),
+		FirstMessageDeliveriesCap:    math.Max(1, perSlot*20/4),
+
+		MeshMessageDeliveriesWeight:     -1,
+		MeshMessageDeliveriesDecay:      decay(5),
+		MeshMessageDeliveriesCap:        meshThreshold * 4,
+		MeshMessageDeliveriesThreshold:  meshThreshold,
+		MeshMessageDeliveriesWindow:     2 * time.Second,
+		MeshMessageDeliveriesActivation: 16 * slotDuration,
+
+		MeshFailurePenaltyWeight: -1,
+		MeshFailurePenaltyDecay:  decay(5),
+
+		InvalidMessageDeliveriesWeight: -140,
+		InvalidMessageDeliveriesDecay:  decay(50),
+	}
+}
+
+// SetScoreParams installs topic score parameters on the joined topics.
+// Attestation traffic scales with the number of validators.
+func (t *Topics) SetScoreParams(numValidators int) error {
+	if err := t.Block.SetScoreParams(topicScoreParams(0.5, 1)); err != nil {
+		return err
+	}
+	if err := t.Attestation.SetScoreParams(topicScoreParams(1, float64(max(numValidators, 1)))); err != nil {
+		return err
+	}
+	if t.AggregateAttestation != nil {
+		if err := t.AggregateAttestation.SetScoreParams(topicScoreParams(0.5, 1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PeerScores holds the latest gossipsub score of each peer.
+type PeerScores struct {
+	// AppSpecific, if set, adds an application score to each peer, e.g.
+	// penalties for req/resp misbehaviour. It must be set before the
+	// gossipsub router starts and must not block.
+	AppSpecific func(peer.ID) float64
+
+	mu     sync.RWMutex
+	scores map[peer.ID]float64
+}
+
+// NewPeerScores returns an empty score table.
+func NewPeerScores() *PeerScores {
+	return &PeerScores{scores: make(map[peer.ID]float64)}
+}
+
+// Score returns the last known score of p.
+func (s *PeerScores) Score(p peer.ID) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	score, ok := s.scores[p]
+	return score, ok
+}
+
+func (s *PeerScores) appSpecificScore(p peer.ID) float64 {
+	if s.AppSpecific == nil {
+		return 0
+	}
+	return s.AppSpecific(p)
+}
+
+// scoreBands are the score bands peers are counted in, from the gossipsub
+// thresholds down. Each peer is in the first band its score reaches.
+var scoreBands = []struct {
+	name string
+	min  float64
+}{
+	{"positive", 0},
+	{"negative", PeerScoreThresholds.GossipThreshold},
+	{"gossip_blocked", PeerScoreThresholds.PublishThreshold},
+	{"publish_blocked", PeerScoreThresholds.GraylistThreshold},
+	{"graylisted", math.Inf(-1)},
+}
+
+// update is the gossipsub score inspector. Scores are exported per peer and
+// as peer counts per band. The per-peer gauge is reset on every inspection,
+// so it only holds the peers gossipsub currently scores.
+func (s *PeerScores) update(snapshots map[peer.ID]*pubsub.PeerScoreSnapshot) {
+	scores := make(map[peer.ID]float64, len(snapshots))
+	bands := make(map[string]int, len(scoreBands))
+	metrics.GossipPeerScore.Reset()
+	for p, snap := range snapshots {
+		scores[p] = snap.Score
+		metrics.GossipPeerScore.WithLabelValues(p.String()).Set(snap.Score)
+		for _, band := range scoreBands {
+			if snap.Score >= band.min {
+				bands[band.name]++
+				break
+			}
+		}
+		gossipLog.Debug("peer score",
+			"peer_id", p.String(),
+			"score", snap.Score,
+			"behaviour_penalty", snap.BehaviourPenalty,
+			"ip_colocation", snap.IPColocationFactor,
+		)
+	}
+	for _, band := range scoreBands {
+		metrics.GossipPeersByScore.WithLabelValues(band.name).Set(float64(bands[band.name]))
+	}
+	s.mu.Lock()
+	s.scores = scores
+	s.mu.Unlock()
+}
+
+var gossipLog = logging.NewComponentLogger(logging.CompGossip)
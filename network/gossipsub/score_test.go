@@ -0,0 +1,35 @@
+package gossipsub_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libp2p/go-libp2p"
+
+	"github.com/geanlabs/gean/network/gossipsub"
+)
+
+func TestScoreParamsAreValid(t *testing.T) {
+	h, err := libp2p.New(libp2p.NoListenAddrs)
+	if err != nil {
+		t.Fatalf("libp2p.New: %v", err)
+	}
+	defer h.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Both NewGossipSub and SetScoreParams validate the parameters.
+	ps, err := gossipsub.NewGossipSub(ctx, h, gossipsub.NewPeerScores())
+	if err != nil {
+		t.Fatalf("NewGossipSub: %v", err)
+	}
+	topics, err := gossipsub.JoinTopics(ps, "devnet0")
+	if err != nil {
+		t.Fatalf("JoinTopics: %v", err)
+	}
+	for _, n := range []int{0, 1, 4096} {
+		if err := topics.SetScoreParams(n); err != nil {
+			t.Fatalf("SetScoreParams(%d): %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gossipsub
+
+import (
+	"context"
+
+	"github.com/golang/snappy"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+// GossipValidator decides whether decoded gossip messages are propagated.
+// A nil function accepts every message that decodes.
+//
+// Messages that fail to decode are rejected. Accepted messages carry the
+// decoded value in pubsub.Message.ValidatorData, so subscribers don't decode
+// them again.
+type GossipValidator struct {
+	// ValidateBlock also receives the peer that forwarded the block, so
+	// blocks with unknown parents can be chased from it.
+	ValidateBlock                 func(peer.ID, *types.SignedBlockWithAttestation) pubsub.ValidationResult
+	ValidateAttestation           func(*types.SignedAttestation) pubsub.ValidationResult
+	ValidateAggregatedAttestation func(*types.AggregatedAttestation) pubsub.ValidationResult
+}
+
+// RegisterValidators installs topic validators on ps for the joined topics.
+// Messages published by self are accepted without checks.
+func RegisterValidators(ps *pubsub.PubSub, self peer.ID, topics *Topics, v *GossipValidator) error {
+	if err := ps.RegisterTopicValidator(topics.Block.String(), topicValidator(self, "block",
+		func(data []byte) (any, error) {
+			sb := new(types.SignedBlockWithAttestation)
+			return sb, sb.UnmarshalSSZ(data)
+		},
+		func(from peer.ID, msg any) pubsub.ValidationResult {
+			if v.ValidateBlock == nil {
+				return pubsub.ValidationAccept
+			}
+			return v.ValidateBlock(from, msg.(*types.SignedBlockWithAttestation))
+		},
+	)); err != nil {
+		return err
+	}
+	if err := ps.RegisterTopicValidator(topics.Attestation.String(), topicValidator(self, "attestation",
+		func(data []byte) (any, error) {
+			sa := new(types.SignedAttestation)
+			return sa, sa.UnmarshalSSZ(data)
+		},
+		func(_ peer.ID, msg any) pubsub.ValidationResult {
+			if v.ValidateAttestation == nil {
+				return pubsub.ValidationAccept
+			}
+			return v.ValidateAttestation(msg.(*types.SignedAttestation))
+		},
+	)); err != nil {
+		return err
+	}
+	if topics.AggregateAttestation != nil {
+		if err := ps.RegisterTopicValidator(topics.AggregateAttestation.String(), topicValidator(self, "aggregate_attestation",
+			func(data []byte) (any, error) { return DecodeAggregatedAttestation(data) },
+			func(_ peer.ID, msg any) pubsub.ValidationResult {
+				if v.ValidateAggregatedAttestation == nil {
+					return pubsub.ValidationAccept
+				}
+				return v.ValidateAggregatedAttestation(msg.(*types.AggregatedAttestation))
+			},
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topicValidator snappy-decodes and decodes a message, then validates it.
+func topicValidator(
+	self peer.ID,
+	kind string,
+	decode func([]byte) (any, error),
+	validate func(peer.ID, any) pubsub.ValidationResult,
+) pubsub.ValidatorEx {
+	return func(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		result := pubsub.ValidationReject
+		defer func() { metrics.GossipValidations.WithLabelValues(kind, resultLabel(result)).Inc() }()
+
+		data, err := snappy.Decode(nil, msg.Data)
+		if err != nil {
+			return result
+		}
+		decoded, err := decode(data)
+		if err != nil {
+			return result
+		}
+		msg.ValidatorData = decoded
+		if from == self {
+			result = pubsub.ValidationAccept
+			return result
+		}
+		result = validate(from, decoded)
+		return result
+	}
+}
+
+func resultLabel(r pubsub.ValidationResult) string {
+	switch r {
+	case pubsub.ValidationAccept:
+		return "accept"
+	case pubsub.ValidationIgnore:
+		return "ignore"
+	default:
+		return "reject"
+	}
+}
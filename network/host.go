@@ -18,6 +18,7 @@ import (
 
 	"github.com/geanlabs/gean/network/gossipsub"
 	"github.com/geanlabs/gean/network/p2p"
+	"github.com/geanlabs/gean/network/reqresp"
 	"github.com/geanlabs/gean/observability/logging"
 )
 
@@ -32,8 +33,12 @@ const nodeKeyFilePerms = 0600
 type Host struct {
 	P2P    host.Host
 	PubSub *pubsub.PubSub
-	Ctx    context.Context
-	Cancel context.CancelFunc
+	Scores *gossipsub.PeerScores
+	// Limiter rate limits req/resp requests; its penalties count towards
+	// each peer's gossipsub score.
+	Limiter *reqresp.RateLimiter
+	Ctx     context.Context
+	Cancel  context.CancelFunc
 }
 
 // NewHost creates a libp2p host with QUIC transport and secp256k1 identity.
@@ -61,14 +66,17 @@ func NewHost(listenAddr string, nodeKeyPath string, bootnodes []string) (*Host,
 		return nil, fmt.Errorf("new host: %w", err)
 	}
 
-	gs, err := gossipsub.NewGossipSub(ctx, h)
+	limiter := reqresp.NewRateLimiter()
+	scores := gossipsub.NewPeerScores()
+	scores.AppSpecific = limiter.Penalty
+	gs, err := gossipsub.NewGossipSub(ctx, h, scores)
 	if err != nil {
 		h.Close()
 		cancel()
 		return nil, fmt.Errorf("gossipsub: %w", err)
 	}
 
-	return &Host{P2P: h, PubSub: gs, Ctx: ctx, Cancel: cancel}, nil
+	return &Host{P2P: h, PubSub: gs, Scores: scores, Limiter: limiter, Ctx: ctx, Cancel: cancel}, nil
 }
 
 // Close shuts down the host.
@@ -102,6 +110,20 @@ func ConnectBootnodes(ctx context.Context, h host.Host, addrs []string) {
 	}
 }
 
+// BootnodeAddrInfos parses bootnode addresses (multiaddr or ENR), skipping invalid ones.
+func BootnodeAddrInfos(addrs []string) []peer.AddrInfo {
+	var infos []peer.AddrInfo
+	for _, addr := range addrs {
+		pi, err := parseBootnode(addr)
+		if err != nil {
+			netLog.Warn("invalid bootnode", "addr", addr, "err", err)
+			continue
+		}
+		infos = append(infos, *pi)
+	}
+	return infos
+}
+
 func parseBootnode(addr string) (*peer.AddrInfo, error) {
 	if strings.HasPrefix(addr, "enr:") {
 		return p2p.ENRToAddrInfo(addr)
@@ -73,14 +73,17 @@ func (s *DiscoveryService) Close() {
 	s.udp.Close()
 }
 
-// LookupRandom finds random nodes in the DHT.
+// LookupRandom finds random nodes in the DHT, keeping only those on our
+// network.
 func (s *DiscoveryService) LookupRandom() []*enode.Node {
 	iter := s.udp.RandomNodes()
 	defer iter.Close()
 
 	var nodes []*enode.Node
 	for i := 0; i < 16 && iter.Next(); i++ {
-		nodes = append(nodes, iter.Node())
+		if node := iter.Node(); s.manager.SameNetwork(node) {
+			nodes = append(nodes, node)
+		}
 	}
 	return nodes
 }
@@ -14,11 +14,22 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 )
 
+// ENR entry keys set by gean.
+const (
+	// ForkDigestENRKey holds the 4-byte fork digest of the node's network.
+	ForkDigestENRKey = "lean"
+	// ClientENRKey holds the client name and version.
+	ClientENRKey = "client"
+)
+
 // LocalNodeManager manages the local node's ENR and identity.
 type LocalNodeManager struct {
 	db      *enode.DB
 	local   *enode.LocalNode
 	privKey *ecdsa.PrivateKey
+
+	forkDigest    [4]byte
+	hasForkDigest bool
 }
 
 // NewLocalNodeManager creates a new local node manager.
@@ -47,9 +58,6 @@ func NewLocalNodeManager(dbPath string, nodeKeyPath string, ip net.IP, udpPort i
 		local.Set(enr.TCP(tcpPort))
 	}
 
-	// Add a custom field to identify "gean" nodes?
-	// local.Set(enr.WithEntry("client", "gean"))
-
 	return &LocalNodeManager{
 		db:      db,
 		local:   local,
@@ -61,6 +69,44 @@ func (m *LocalNodeManager) Node() *enode.Node {
 	return m.local.Node()
 }
 
+// SetForkDigest advertises the node's network in the ENR. Once set,
+// discovery only returns nodes advertising the same digest.
+func (m *LocalNodeManager) SetForkDigest(digest [4]byte) {
+	m.local.Set(enr.WithEntry(ForkDigestENRKey, digest[:]))
+	m.forkDigest = digest
+	m.hasForkDigest = true
+}
+
+// SetClient advertises the client name and version in the ENR.
+func (m *LocalNodeManager) SetClient(name, version string) {
+	m.local.Set(enr.WithEntry(ClientENRKey, []string{name, version}))
+}
+
+// SameNetwork reports whether a node advertises our fork digest. Every node
+// matches until SetForkDigest is called.
+func (m *LocalNodeManager) SameNetwork(node *enode.Node) bool {
+	if !m.hasForkDigest {
+		return true
+	}
+	digest, ok := NodeForkDigest(node)
+	return ok && digest == m.forkDigest
+}
+
+// NodeForkDigest returns the fork digest a node advertises in its ENR.
+func NodeForkDigest(node *enode.Node) ([4]byte, bool) {
+	var raw []byte
+	if err := node.Record().Load(enr.WithEntry(ForkDigestENRKey, &raw)); err != nil || len(raw) != 4 {
+		return [4]byte{}, false
+	}
+	return [4]byte(raw), true
+}
+
+// Seq returns the sequence number of the local ENR. It increases every time
+// the record changes, and doubles as the req/resp metadata sequence number.
+func (m *LocalNodeManager) Seq() uint64 {
+	return m.local.Seq()
+}
+
 func (m *LocalNodeManager) Database() *enode.DB {
 	return m.db
 }
@@ -0,0 +1,42 @@
+package p2p_test
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/geanlabs/gean/network/p2p"
+)
+
+func newTestManager(t *testing.T) *p2p.LocalNodeManager {
+	t.Helper()
+	dir := t.TempDir()
+	m, err := p2p.NewLocalNodeManager(filepath.Join(dir, "db"), filepath.Join(dir, "node.key"), net.IPv4(127, 0, 0, 1), 9000, 0)
+	if err != nil {
+		t.Fatalf("NewLocalNodeManager: %v", err)
+	}
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestForkDigestInENR(t *testing.T) {
+	a, b, c := newTestManager(t), newTestManager(t), newTestManager(t)
+	digest := [4]byte{0xde, 0xad, 0xbe, 0xef}
+	a.SetForkDigest(digest)
+	b.SetForkDigest(digest)
+	c.SetForkDigest([4]byte{0x01, 0x02, 0x03, 0x04})
+
+	got, ok := p2p.NodeForkDigest(a.Node())
+	if !ok || got != digest {
+		t.Fatalf("NodeForkDigest = %x, %v, want %x, true", got, ok, digest)
+	}
+	if !a.SameNetwork(b.Node()) {
+		t.Fatal("node with the same digest rejected")
+	}
+	if a.SameNetwork(c.Node()) {
+		t.Fatal("node with a different digest accepted")
+	}
+	if a.SameNetwork(newTestManager(t).Node()) {
+		t.Fatal("node without a digest accepted")
+	}
+}
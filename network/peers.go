@@ -0,0 +1,262 @@
+package network
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/network/gossipsub"
+	"github.com/geanlabs/gean/network/p2p"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/observability/metrics"
+)
+
+const (
+	// peerManagerInterval is how often the peer manager prunes, redials and
+	// tops up connections.
+	peerManagerInterval = 5 * time.Second
+	// dialTimeout bounds a single outbound dial.
+	dialTimeout = 10 * time.Second
+	// redialBackoff is the first redial delay; it doubles up to maxRedialBackoff.
+	redialBackoff    = 5 * time.Second
+	maxRedialBackoff = 5 * time.Minute
+	// maxRedialAttempts is how many times a lost, non-static peer is redialed
+	// before it is forgotten. Static peers are redialed forever.
+	maxRedialAttempts = 5
+)
+
+// PeerManager keeps the node connected to between TargetPeers and MaxPeers
+// peers. It dials peers found by discovery when below target, redials lost
+// peers with exponential backoff, and disconnects peers whose gossip score
+// falls below the graylist threshold or that exceed MaxPeers.
+type PeerManager struct {
+	Host        host.Host
+	TargetPeers int
+	MaxPeers    int
+
+	// Static peers (bootnodes) are always redialed when lost.
+	Static []peer.AddrInfo
+	// Discover, if set, returns candidate nodes when below TargetPeers.
+	Discover func() []*enode.Node
+	// Score, if set, returns a peer's gossip score for pruning.
+	Score func(peer.ID) (float64, bool)
+
+	mu     sync.Mutex
+	redial map[peer.ID]*redialState
+	static map[peer.ID]bool
+}
+
+type redialState struct {
+	info     peer.AddrInfo
+	attempts int
+	next     time.Time
+}
+
+// Start registers for connection events and runs the manager until ctx is
+// cancelled.
+func (m *PeerManager) Start(ctx context.Context) {
+	m.redial = make(map[peer.ID]*redialState)
+	m.static = make(map[peer.ID]bool)
+	for _, info := range m.Static {
+		m.static[info.ID] = true
+	}
+
+	m.Host.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, c network.Conn) {
+			m.onConnected(ctx, c.RemotePeer())
+		},
+		DisconnectedF: func(n network.Network, c network.Conn) {
+			if n.Connectedness(c.RemotePeer()) != network.Connected {
+				m.onDisconnected(ctx, c.RemotePeer())
+			}
+		},
+	})
+
+	go m.run(ctx)
+}
+
+func (m *PeerManager) run(ctx context.Context) {
+	ticker := time.NewTicker(peerManagerInterval)
+	defer ticker.Stop()
+	for {
+		m.prune(ctx)
+		m.redialLost(ctx)
+		m.dialDiscovered(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *PeerManager) onConnected(ctx context.Context, pid peer.ID) {
+	m.mu.Lock()
+	delete(m.redial, pid)
+	m.mu.Unlock()
+
+	if m.MaxPeers > 0 && len(m.Host.Network().Peers()) > m.MaxPeers && !m.static[pid] {
+		netLog.Debug("too many peers, disconnecting", "peer_id", pid.String())
+		metrics.PeerDisconnects.WithLabelValues("max_peers").Inc()
+		go reqresp.Disconnect(ctx, m.Host, pid, reqresp.GoodbyeTooManyPeers)
+	}
+}
+
+// onDisconnected schedules a redial of a lost peer we know how to reach.
+func (m *PeerManager) onDisconnected(ctx context.Context, pid peer.ID) {
+	if ctx.Err() != nil {
+		return
+	}
+	if score, ok := m.score(pid); ok && score < gossipsub.PeerScoreThresholds.GraylistThreshold {
+		return // dropped for misbehaving; don't come back
+	}
+	addrs := m.Host.Peerstore().Addrs(pid)
+	if len(addrs) == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.redial[pid]; !ok {
+		m.redial[pid] = &redialState{
+			info: peer.AddrInfo{ID: pid, Addrs: addrs},
+			next: time.Now().Add(redialBackoff),
+		}
+	}
+}
+
+// prune disconnects graylisted peers, then the lowest-scoring peers above
+// MaxPeers. Each is sent a goodbye first, without holding up the loop.
+func (m *PeerManager) prune(ctx context.Context) {
+	peers := m.Host.Network().Peers()
+	var keep []peer.ID
+	for _, pid := range peers {
+		if score, ok := m.score(pid); ok && score < gossipsub.PeerScoreThresholds.GraylistThreshold {
+			netLog.Info("disconnecting low-score peer", "peer_id", pid.String(), "score", score)
+			metrics.PeerDisconnects.WithLabelValues("low_score").Inc()
+			go reqresp.Disconnect(ctx, m.Host, pid, reqresp.GoodbyeBadScore)
+			continue
+		}
+		keep = append(keep, pid)
+	}
+
+	if m.MaxPeers <= 0 || len(keep) <= m.MaxPeers {
+		return
+	}
+	sort.Slice(keep, func(i, j int) bool {
+		si, _ := m.score(keep[i])
+		sj, _ := m.score(keep[j])
+		return si < sj
+	})
+	excess := len(keep) - m.MaxPeers
+	for _, pid := range keep {
+		if excess == 0 {
+			break
+		}
+		if m.static[pid] {
+			continue
+		}
+		metrics.PeerDisconnects.WithLabelValues("max_peers").Inc()
+		go reqresp.Disconnect(ctx, m.Host, pid, reqresp.GoodbyeTooManyPeers)
+		excess--
+	}
+}
+
+// redialLost redials static peers that are not connected and lost peers
+// whose backoff has expired.
+func (m *PeerManager) redialLost(ctx context.Context) {
+	now := time.Now()
+	m.mu.Lock()
+	for _, info := range m.Static {
+		if _, ok := m.redial[info.ID]; !ok && !m.connected(info.ID) {
+			m.redial[info.ID] = &redialState{info: info, next: now}
+		}
+	}
+	var due []peer.AddrInfo
+	for pid, st := range m.redial {
+		switch {
+		case m.connected(pid):
+			delete(m.redial, pid)
+		case !now.Before(st.next):
+			st.attempts++
+			st.next = now.Add(min(redialBackoff<<min(st.attempts, 16), maxRedialBackoff))
+			if st.attempts > maxRedialAttempts && !m.static[pid] {
+				delete(m.redial, pid)
+				continue
+			}
+			due = append(due, st.info)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, info := range due {
+		m.dial(ctx, info, "redial")
+	}
+}
+
+// dialDiscovered dials nodes from discovery while below TargetPeers.
+func (m *PeerManager) dialDiscovered(ctx context.Context) {
+	if m.Discover == nil {
+		return
+	}
+	need := m.TargetPeers - len(m.Host.Network().Peers())
+	if need <= 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range m.Discover() {
+		if need == 0 {
+			break
+		}
+		info, err := p2p.ENRToAddrInfo(node.String())
+		if err != nil {
+			continue
+		}
+		if info.ID == m.Host.ID() || m.connected(info.ID) || m.backingOff(info.ID) {
+			continue
+		}
+		need--
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.dial(ctx, *info, "discovery")
+		}()
+	}
+	wg.Wait()
+}
+
+func (m *PeerManager) dial(ctx context.Context, info peer.AddrInfo, source string) {
+	dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	if err := m.Host.Connect(dctx, info); err != nil {
+		netLog.Debug("dial failed", "peer_id", info.ID.String(), "source", source, "err", err)
+		metrics.PeerDials.WithLabelValues(source, "failure").Inc()
+		return
+	}
+	netLog.Debug("dialed peer", "peer_id", info.ID.String(), "source", source)
+	metrics.PeerDials.WithLabelValues(source, "success").Inc()
+}
+
+func (m *PeerManager) connected(pid peer.ID) bool {
+	return m.Host.Network().Connectedness(pid) == network.Connected
+}
+
+func (m *PeerManager) backingOff(pid peer.ID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.redial[pid]
+	return ok
+}
+
+func (m *PeerManager) score(pid peer.ID) (float64, bool) {
+	if m.Score == nil {
+		return 0, false
+	}
+	return m.Score(pid)
+}
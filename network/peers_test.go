@@ -0,0 +1,61 @@
+package network_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/network"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPeerManagerDialsStaticPeers(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := &network.PeerManager{
+		Host:        a,
+		TargetPeers: 4,
+		MaxPeers:    4,
+		Static:      []peer.AddrInfo{{ID: b.ID(), Addrs: b.Addrs()}},
+	}
+	m.Start(ctx)
+
+	waitFor(t, "static peer to be dialed", func() bool {
+		return slices.Contains(a.Network().Peers(), b.ID())
+	})
+}
+
+func TestPeerManagerEnforcesMaxPeers(t *testing.T) {
+	a := testutil.NewHost(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := &network.PeerManager{Host: a, TargetPeers: 1, MaxPeers: 1}
+	m.Start(ctx)
+
+	for range 3 {
+		other := testutil.NewHost(t)
+		if err := other.Connect(ctx, peer.AddrInfo{ID: a.ID(), Addrs: a.Addrs()}); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+	}
+	waitFor(t, "peer count to drop to 1", func() bool {
+		return len(a.Network().Peers()) <= 1
+	})
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 
+	"github.com/geanlabs/gean/observability/metrics"
 	"github.com/geanlabs/gean/types"
 )
 
@@ -30,12 +33,9 @@ func RequestStatus(ctx context.Context, h host.Host, pid peer.ID, status Status)
 		return nil, fmt.Errorf("close write: %w", err)
 	}
 
-	code, err := ReadResponseCode(s)
-	if err != nil {
-		return nil, fmt.Errorf("read response code: %w", err)
-	}
-	if code != ResponseSuccess {
-		return nil, fmt.Errorf("peer returned error code %d", code)
+	setChunkDeadline(ctx, s)
+	if err := readSingleResponse(s); err != nil {
+		return nil, err
 	}
 
 	resp, err := ReadStatus(s)
@@ -68,18 +68,165 @@ func RequestBlocksByRoot(ctx context.Context, h host.Host, pid peer.ID, roots []
 		return nil, fmt.Errorf("close write: %w", err)
 	}
 
-	// Read block responses until EOF. Each response is prefixed with a status byte.
+	return readBlockChunks(ctx, s)
+}
+
+// RequestBlocksByRange requests the canonical blocks with slots in
+// [startSlot, startSlot+count) from a peer. count is capped at
+// types.MaxRequestBlocks; callers needing more must issue several requests.
+func RequestBlocksByRange(ctx context.Context, h host.Host, pid peer.ID, startSlot, count uint64) ([]*types.SignedBlockWithAttestation, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	count = min(count, types.MaxRequestBlocks)
+
+	ctx, cancel := context.WithTimeout(ctx, reqRespTimeout)
+	defer cancel()
+
+	s, err := h.NewStream(ctx, pid, protocol.ID(BlocksByRangeProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("open stream: %w", err)
+	}
+	defer s.Close()
+
+	if err := WriteBlocksByRangeRequest(s, BlocksByRangeRequest{StartSlot: startSlot, Count: count}); err != nil {
+		return nil, fmt.Errorf("write request: %w", err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		return nil, fmt.Errorf("close write: %w", err)
+	}
+
+	blocks, err := readBlockChunks(ctx, s)
+	if err != nil {
+		return blocks, err
+	}
+
+	// Chunks must be in the requested range, in strictly increasing slot order.
+	if uint64(len(blocks)) > count {
+		return nil, fmt.Errorf("peer returned %d blocks, requested %d", len(blocks), count)
+	}
+	prevSlot := uint64(0)
+	for i, sb := range blocks {
+		slot := sb.Message.Block.Slot
+		if slot < startSlot || slot-startSlot >= count || (i > 0 && slot <= prevSlot) {
+			return nil, fmt.Errorf("peer returned block at slot %d outside requested range or out of order", slot)
+		}
+		prevSlot = slot
+	}
+	return blocks, nil
+}
+
+// RequestPing sends our metadata sequence number to a peer and returns theirs.
+func RequestPing(ctx context.Context, h host.Host, pid peer.ID, seq uint64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	s, err := h.NewStream(ctx, pid, protocol.ID(PingProtocol))
+	if err != nil {
+		return 0, fmt.Errorf("open stream: %w", err)
+	}
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(pingTimeout))
+
+	if err := WritePing(s, seq); err != nil {
+		return 0, fmt.Errorf("write ping: %w", err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		return 0, fmt.Errorf("close write: %w", err)
+	}
+
+	setChunkDeadline(ctx, s)
+	if err := readSingleResponse(s); err != nil {
+		return 0, err
+	}
+	peerSeq, err := ReadPing(s)
+	if err != nil {
+		return 0, fmt.Errorf("read response: %w", err)
+	}
+	return peerSeq, nil
+}
+
+// RequestMetadata fetches a peer's metadata.
+func RequestMetadata(ctx context.Context, h host.Host, pid peer.ID) (*Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, reqRespTimeout)
+	defer cancel()
+
+	s, err := h.NewStream(ctx, pid, protocol.ID(MetadataProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("open stream: %w", err)
+	}
+	defer s.Close()
+
+	if err := s.CloseWrite(); err != nil {
+		return nil, fmt.Errorf("close write: %w", err)
+	}
+
+	setChunkDeadline(ctx, s)
+	if err := readSingleResponse(s); err != nil {
+		return nil, err
+	}
+	md, err := ReadMetadata(s)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return &md, nil
+}
+
+// SendGoodbye tells a peer why we are about to disconnect. It returns once
+// the peer has closed the stream or goodbyeTimeout has passed.
+func SendGoodbye(ctx context.Context, h host.Host, pid peer.ID, reason uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, goodbyeTimeout)
+	defer cancel()
+
+	s, err := h.NewStream(ctx, pid, protocol.ID(GoodbyeProtocol))
+	if err != nil {
+		return fmt.Errorf("open stream: %w", err)
+	}
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(goodbyeTimeout))
+
+	if err := WriteGoodbye(s, reason); err != nil {
+		return fmt.Errorf("write goodbye: %w", err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		return fmt.Errorf("close write: %w", err)
+	}
+	metrics.PeerGoodbyes.WithLabelValues("sent", GoodbyeReasonString(reason)).Inc()
+
+	// Wait for the peer to close its side, so the goodbye is read before
+	// the connection goes away.
+	io.Copy(io.Discard, s)
+	return nil
+}
+
+// Disconnect sends a goodbye with the given reason, then closes every
+// connection to the peer whether or not the goodbye got through.
+func Disconnect(ctx context.Context, h host.Host, pid peer.ID, reason uint64) {
+	SendGoodbye(ctx, h, pid, reason)
+	h.Network().ClosePeer(pid)
+}
+
+// readSingleResponse reads the response code of a single-chunk response.
+func readSingleResponse(r io.Reader) error {
+	err := ReadResponse(r)
+	if err == io.EOF {
+		return fmt.Errorf("read response code: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// readBlockChunks reads signed block responses until EOF. Each response is
+// prefixed with a status byte; an error response ends the stream and is
+// returned along with the blocks read before it.
+func readBlockChunks(ctx context.Context, s network.Stream) ([]*types.SignedBlockWithAttestation, error) {
 	var blocks []*types.SignedBlockWithAttestation
 	for {
-		code, err := ReadResponseCode(s)
-		if err != nil {
+		setChunkDeadline(ctx, s)
+		if err := ReadResponse(s); err != nil {
 			if err == io.EOF {
-				break
+				return blocks, nil
 			}
-			return blocks, fmt.Errorf("read response code: %w", err)
-		}
-		if code != ResponseSuccess {
-			break
+			return blocks, err
 		}
 		data, err := ReadSnappyFrame(s)
 		if err != nil {
@@ -87,9 +234,18 @@ func RequestBlocksByRoot(ctx context.Context, h host.Host, pid peer.ID, roots []
 		}
 		block := new(types.SignedBlockWithAttestation)
 		if err := block.UnmarshalSSZ(data); err != nil {
-			continue
+			return blocks, fmt.Errorf("decode block: %w", err)
 		}
 		blocks = append(blocks, block)
 	}
-	return blocks, nil
+}
+
+// setChunkDeadline gives the next response chunk chunkTimeout to arrive,
+// but never beyond the deadline of the whole request.
+func setChunkDeadline(ctx context.Context, s network.Stream) {
+	deadline := time.Now().Add(chunkTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	s.SetReadDeadline(deadline)
 }
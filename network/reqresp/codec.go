@@ -37,9 +37,86 @@ func WriteStatus(w io.Writer, status Status) error {
 	return WriteSnappyFrame(w, buf[:])
 }
 
+// ReadGoodbye reads and decodes a snappy-framed goodbye reason.
+func ReadGoodbye(r io.Reader) (uint64, error) {
+	return readUint64(r, "goodbye")
+}
+
+// WriteGoodbye encodes and writes a snappy-framed goodbye reason.
+func WriteGoodbye(w io.Writer, reason uint64) error {
+	return writeUint64(w, reason)
+}
+
+// ReadPing reads and decodes a snappy-framed ping sequence number.
+func ReadPing(r io.Reader) (uint64, error) {
+	return readUint64(r, "ping")
+}
+
+// WritePing encodes and writes a snappy-framed ping sequence number.
+func WritePing(w io.Writer, seq uint64) error {
+	return writeUint64(w, seq)
+}
+
+// ReadMetadata reads and decodes a snappy-framed metadata message: an SSZ
+// container of the sequence number, the attestation subnet bitvector and
+// the client name as a byte list.
+func ReadMetadata(r io.Reader) (Metadata, error) {
+	data, err := ReadSnappyFrame(r)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if len(data) < 20 || len(data)-20 > MaxClientLength {
+		return Metadata{}, fmt.Errorf("invalid metadata length: %d", len(data))
+	}
+	if offset := binary.LittleEndian.Uint32(data[16:20]); offset != 20 {
+		return Metadata{}, fmt.Errorf("invalid metadata client offset: %d", offset)
+	}
+	return Metadata{
+		SeqNumber: binary.LittleEndian.Uint64(data[0:8]),
+		Attnets:   binary.LittleEndian.Uint64(data[8:16]),
+		Client:    string(data[20:]),
+	}, nil
+}
+
+// WriteMetadata encodes and writes a snappy-framed metadata message.
+func WriteMetadata(w io.Writer, md Metadata) error {
+	if len(md.Client) > MaxClientLength {
+		return fmt.Errorf("client name too long: %d", len(md.Client))
+	}
+	buf := make([]byte, 20, 20+len(md.Client))
+	binary.LittleEndian.PutUint64(buf[0:8], md.SeqNumber)
+	binary.LittleEndian.PutUint64(buf[8:16], md.Attnets)
+	binary.LittleEndian.PutUint32(buf[16:20], 20)
+	buf = append(buf, md.Client...)
+	return WriteSnappyFrame(w, buf)
+}
+
+func readUint64(r io.Reader, name string) (uint64, error) {
+	data, err := ReadSnappyFrame(r)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("invalid %s length: %d", name, len(data))
+	}
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+func writeUint64(w io.Writer, v uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return WriteSnappyFrame(w, buf[:])
+}
+
+// writeSignedBlock writes a successful response chunk carrying a block. A
+// block that fails to encode is answered with ResponseServerError instead.
 func writeSignedBlock(w io.Writer, block *types.SignedBlockWithAttestation) error {
 	data, err := block.MarshalSSZ()
 	if err != nil {
+		WriteErrorResponse(w, ResponseServerError, "block encoding failed")
+		return err
+	}
+	if _, err := w.Write([]byte{ResponseSuccess}); err != nil {
 		return err
 	}
 	return WriteSnappyFrame(w, data)
@@ -64,6 +141,45 @@ func readBlocksByRootRequest(r io.Reader) ([][32]byte, error) {
 	return roots, nil
 }
 
+// ReadBlocksByRangeRequest reads and decodes a snappy-framed blocks_by_range request.
+func ReadBlocksByRangeRequest(r io.Reader) (BlocksByRangeRequest, error) {
+	data, err := ReadSnappyFrame(r)
+	if err != nil {
+		return BlocksByRangeRequest{}, err
+	}
+	if len(data) != 16 {
+		return BlocksByRangeRequest{}, fmt.Errorf("invalid blocks_by_range request length: %d", len(data))
+	}
+	req := BlocksByRangeRequest{
+		StartSlot: binary.LittleEndian.Uint64(data[0:8]),
+		Count:     binary.LittleEndian.Uint64(data[8:16]),
+	}
+	if req.Count == 0 || req.Count > types.MaxRequestBlocks {
+		return BlocksByRangeRequest{}, fmt.Errorf("invalid blocks_by_range count: %d", req.Count)
+	}
+	return req, nil
+}
+
+// WriteBlocksByRangeRequest encodes and writes a snappy-framed blocks_by_range request.
+func WriteBlocksByRangeRequest(w io.Writer, req BlocksByRangeRequest) error {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[0:8], req.StartSlot)
+	binary.LittleEndian.PutUint64(buf[8:16], req.Count)
+	return WriteSnappyFrame(w, buf[:])
+}
+
+// WriteErrorResponse writes an error response code followed by the error
+// message, an SSZ byte list truncated to MaxErrorMessageLength, snappy-framed.
+func WriteErrorResponse(w io.Writer, code byte, message string) error {
+	if len(message) > MaxErrorMessageLength {
+		message = message[:MaxErrorMessageLength]
+	}
+	if _, err := w.Write([]byte{code}); err != nil {
+		return err
+	}
+	return WriteSnappyFrame(w, []byte(message))
+}
+
 // ReadResponseCode reads a single response status byte.
 func ReadResponseCode(r io.Reader) (byte, error) {
 	var buf [1]byte
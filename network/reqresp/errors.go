@@ -0,0 +1,66 @@
+package reqresp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Errors returned by the client for non-success response codes. A
+// *ResponseError carrying the peer's message wraps one of them.
+var (
+	ErrInvalidRequest      = errors.New("invalid request")
+	ErrServerError         = errors.New("server error")
+	ErrResourceUnavailable = errors.New("resource unavailable")
+	ErrUnknownResponseCode = errors.New("unknown response code")
+)
+
+// ResponseError is a non-success response from a peer.
+type ResponseError struct {
+	Code    byte
+	Message string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return e.Unwrap().Error()
+	}
+	return fmt.Sprintf("%v: %s", e.Unwrap(), e.Message)
+}
+
+// Unwrap returns the sentinel error for the response code.
+func (e *ResponseError) Unwrap() error {
+	switch e.Code {
+	case ResponseInvalidRequest:
+		return ErrInvalidRequest
+	case ResponseServerError:
+		return ErrServerError
+	case ResponseResourceUnavailable:
+		return ErrResourceUnavailable
+	default:
+		return ErrUnknownResponseCode
+	}
+}
+
+// ReadResponse reads a response code and, for a non-success code, the
+// error message that follows it. It returns nil on success, io.EOF if the
+// stream ended cleanly before a response, and a *ResponseError otherwise.
+func ReadResponse(r io.Reader) error {
+	code, err := ReadResponseCode(r)
+	if err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("read response code: %w", err)
+	}
+	if code == ResponseSuccess {
+		return nil
+	}
+	// The message is informational; a peer that omits it still gets its
+	// response code reported.
+	data, _ := ReadSnappyFrame(r)
+	if len(data) > MaxErrorMessageLength {
+		data = data[:MaxErrorMessageLength]
+	}
+	return &ResponseError{Code: code, Message: string(data)}
+}
@@ -0,0 +1,68 @@
+package reqresp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/observability/logging"
+)
+
+var log = logging.NewComponentLogger(logging.CompReqResp)
+
+// Handshake exchanges status with every newly connected peer and
+// disconnects, with a goodbye, peers whose chain conflicts with ours.
+type Handshake struct {
+	Host host.Host
+	// Status returns our current status.
+	Status func() Status
+	// Check returns an error if the peer's status conflicts with our chain,
+	// e.g. a different finalized block at a slot we have finalized.
+	Check func(Status) error
+	// OnStatus, if set, is called with the status of each compatible peer
+	// after the handshake that follows a new connection.
+	OnStatus func(peer.ID, Status)
+}
+
+// Start registers a connection notifier that runs the handshake in a new
+// goroutine for every new peer until ctx is cancelled.
+func (hs *Handshake) Start(ctx context.Context) {
+	hs.Host.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(n network.Network, c network.Conn) {
+			pid := c.RemotePeer()
+			if ctx.Err() != nil || len(n.ConnsToPeer(pid)) > 1 {
+				return // shutting down, or already handshaken on another connection
+			}
+			go func() {
+				status, err := hs.Run(ctx, pid)
+				if err != nil {
+					log.Debug("status handshake failed", "peer_id", pid.String(), "err", err)
+					return
+				}
+				if hs.OnStatus != nil {
+					hs.OnStatus(pid, *status)
+				}
+			}()
+		},
+	})
+}
+
+// Run exchanges status with a peer and checks it against our chain. A peer
+// that fails the check is sent a goodbye and disconnected.
+func (hs *Handshake) Run(ctx context.Context, pid peer.ID) (*Status, error) {
+	status, err := RequestStatus(ctx, hs.Host, pid, hs.Status())
+	if err != nil {
+		return nil, err
+	}
+	if hs.Check != nil {
+		if err := hs.Check(*status); err != nil {
+			log.Info("disconnecting peer on a conflicting chain", "peer_id", pid.String(), "err", err)
+			Disconnect(ctx, hs.Host, pid, GoodbyeIrrelevantNetwork)
+			return nil, fmt.Errorf("incompatible status: %w", err)
+		}
+	}
+	return status, nil
+}
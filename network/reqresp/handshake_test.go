@@ -0,0 +1,94 @@
+package reqresp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/types"
+)
+
+func testStatus(finalizedRoot byte) reqresp.Status {
+	return reqresp.Status{
+		Finalized: &types.Checkpoint{Root: [32]byte{finalizedRoot}},
+		Head:      &types.Checkpoint{Root: [32]byte{finalizedRoot}},
+	}
+}
+
+func TestHandshakeDisconnectsConflictingPeerWithGoodbye(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	goodbyes := make(chan uint64, 1)
+	reqresp.RegisterReqResp(b, &reqresp.ReqRespHandler{
+		OnStatus:  func(reqresp.Status) reqresp.Status { return testStatus(0x02) },
+		OnGoodbye: func(_ peer.ID, reason uint64) { goodbyes <- reason },
+	})
+
+	hs := &reqresp.Handshake{
+		Host:   a,
+		Status: func() reqresp.Status { return testStatus(0x01) },
+		Check: func(s reqresp.Status) error {
+			if s.Finalized.Root != [32]byte{0x01} {
+				return errors.New("different finalized root")
+			}
+			return nil
+		},
+		OnStatus: func(peer.ID, reqresp.Status) { t.Error("OnStatus called for a conflicting peer") },
+	}
+	hs.Start(ctx)
+
+	if err := a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	select {
+	case reason := <-goodbyes:
+		if reason != reqresp.GoodbyeIrrelevantNetwork {
+			t.Fatalf("goodbye reason = %d, want %d", reason, reqresp.GoodbyeIrrelevantNetwork)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no goodbye received")
+	}
+
+	waitFor(t, "conflicting peer to be disconnected", func() bool {
+		return a.Network().Connectedness(b.ID()) != network.Connected
+	})
+}
+
+func TestHandshakeReportsCompatiblePeer(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reqresp.RegisterReqResp(b, &reqresp.ReqRespHandler{
+		OnStatus: func(reqresp.Status) reqresp.Status { return testStatus(0x01) },
+	})
+
+	statuses := make(chan peer.ID, 1)
+	hs := &reqresp.Handshake{
+		Host:     a,
+		Status:   func() reqresp.Status { return testStatus(0x01) },
+		Check:    func(reqresp.Status) error { return nil },
+		OnStatus: func(pid peer.ID, _ reqresp.Status) { statuses <- pid },
+	}
+	hs.Start(ctx)
+
+	if err := a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	select {
+	case pid := <-statuses:
+		if pid != b.ID() {
+			t.Fatalf("OnStatus peer = %s, want %s", pid, b.ID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnStatus not called")
+	}
+}
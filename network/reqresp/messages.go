@@ -3,6 +3,8 @@ package reqresp
 import (
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/peer"
+
 	"github.com/geanlabs/gean/types"
 )
 
@@ -11,6 +13,10 @@ const (
 	StatusProtocol             = "/leanconsensus/req/status/1/ssz_snappy"
 	BlocksByRootProtocol       = "/leanconsensus/req/lean_blocks_by_root/1/ssz_snappy"
 	BlocksByRootProtocolLegacy = "/leanconsensus/req/blocks_by_root/1/ssz_snappy"
+	BlocksByRangeProtocol      = "/leanconsensus/req/lean_blocks_by_range/1/ssz_snappy"
+	GoodbyeProtocol            = "/leanconsensus/req/goodbye/1/ssz_snappy"
+	PingProtocol               = "/leanconsensus/req/ping/1/ssz_snappy"
+	MetadataProtocol           = "/leanconsensus/req/metadata/1/ssz_snappy"
 )
 
 // Response status codes.
@@ -21,7 +27,52 @@ const (
 	ResponseResourceUnavailable = 0x03
 )
 
-const reqRespTimeout = 10 * time.Second
+// Goodbye reason codes, numbered as in the Ethereum consensus p2p spec.
+const (
+	GoodbyeClientShutdown    uint64 = 1
+	GoodbyeIrrelevantNetwork uint64 = 2
+	GoodbyeFaultOrError      uint64 = 3
+	GoodbyeTooManyPeers      uint64 = 129
+	GoodbyeBadScore          uint64 = 250
+)
+
+// GoodbyeReasonString returns a short name for a goodbye reason code.
+func GoodbyeReasonString(reason uint64) string {
+	switch reason {
+	case GoodbyeClientShutdown:
+		return "client_shutdown"
+	case GoodbyeIrrelevantNetwork:
+		return "irrelevant_network"
+	case GoodbyeFaultOrError:
+		return "fault_or_error"
+	case GoodbyeTooManyPeers:
+		return "too_many_peers"
+	case GoodbyeBadScore:
+		return "bad_score"
+	default:
+		return "unknown"
+	}
+}
+
+// MaxErrorMessageLength is the longest error message sent after a
+// non-success response code.
+const MaxErrorMessageLength = 256
+
+// reqRespTimeout bounds a whole request, from opening the stream to the
+// last response chunk. chunkTimeout bounds the wait for each chunk within
+// it, so a peer that stalls mid-response is dropped early.
+const (
+	reqRespTimeout = 10 * time.Second
+	chunkTimeout   = 5 * time.Second
+)
+
+// pingTimeout bounds a ping round trip; a peer slower than this counts as
+// unresponsive.
+const pingTimeout = 5 * time.Second
+
+// goodbyeTimeout bounds sending a goodbye, so disconnects are never held up
+// by a peer that does not read it.
+const goodbyeTimeout = 2 * time.Second
 
 // Status is the status message exchanged between peers.
 type Status struct {
@@ -29,8 +80,35 @@ type Status struct {
 	Head      *types.Checkpoint
 }
 
+// MaxClientLength is the longest client name carried in metadata.
+const MaxClientLength = 64
+
+// Metadata describes a node to its peers. SeqNumber is the node's ENR
+// sequence number and changes whenever the rest of the metadata does.
+type Metadata struct {
+	SeqNumber uint64
+	// Attnets is a bitvector of the attestation subnets the node subscribes to.
+	Attnets uint64
+	// Client is the client name and version, e.g. "gean/v0.1.0".
+	Client string
+}
+
+// BlocksByRangeRequest asks for the canonical blocks with slots in
+// [StartSlot, StartSlot+Count).
+type BlocksByRangeRequest struct {
+	StartSlot uint64
+	Count     uint64
+}
+
 // ReqRespHandler processes incoming request/response messages.
 type ReqRespHandler struct {
-	OnStatus       func(Status) Status
-	OnBlocksByRoot func([][32]byte) []*types.SignedBlockWithAttestation
+	OnStatus        func(Status) Status
+	OnBlocksByRoot  func([][32]byte) []*types.SignedBlockWithAttestation
+	OnBlocksByRange func(BlocksByRangeRequest) []*types.SignedBlockWithAttestation
+	// OnGoodbye, if set, is called when a peer announces it is disconnecting.
+	OnGoodbye func(peer.ID, uint64)
+	// Metadata returns our metadata; ping and metadata are not served if nil.
+	Metadata func() Metadata
+	// Limiter, if set, rate limits requests per peer and protocol.
+	Limiter *RateLimiter
 }
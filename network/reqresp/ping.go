@@ -0,0 +1,133 @@
+package reqresp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+
+	"github.com/geanlabs/gean/observability/metrics"
+)
+
+const (
+	// DefaultPingInterval is how often each connected peer is pinged.
+	DefaultPingInterval = 15 * time.Second
+	// maxPingFailures is how many pings in a row a peer may miss before it
+	// is disconnected as dead.
+	maxPingFailures = 3
+	// metadataKey is the peerstore key under which a peer's Metadata is kept.
+	metadataKey = "lean_metadata"
+)
+
+// PeerMonitor pings connected peers every Interval. A peer that misses
+// maxPingFailures pings in a row is disconnected; a peer whose sequence
+// number has moved past its stored metadata has its metadata refetched
+// into the peerstore.
+type PeerMonitor struct {
+	Host host.Host
+	// Metadata returns our metadata; its SeqNumber is sent in every ping.
+	Metadata func() Metadata
+	// Interval defaults to DefaultPingInterval.
+	Interval time.Duration
+
+	mu       sync.Mutex
+	failures map[peer.ID]int
+}
+
+// Start runs the monitor until ctx is cancelled.
+func (m *PeerMonitor) Start(ctx context.Context) {
+	m.failures = make(map[peer.ID]int)
+	interval := m.Interval
+	if interval == 0 {
+		interval = DefaultPingInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.pingAll(ctx)
+			}
+		}
+	}()
+}
+
+func (m *PeerMonitor) pingAll(ctx context.Context) {
+	peers := m.Host.Network().Peers()
+
+	m.mu.Lock()
+	connected := make(map[peer.ID]bool, len(peers))
+	for _, pid := range peers {
+		connected[pid] = true
+	}
+	for pid := range m.failures {
+		if !connected[pid] {
+			delete(m.failures, pid)
+		}
+	}
+	m.mu.Unlock()
+
+	seq := m.Metadata().SeqNumber
+	var wg sync.WaitGroup
+	for _, pid := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.ping(ctx, pid, seq)
+		}()
+	}
+	wg.Wait()
+}
+
+func (m *PeerMonitor) ping(ctx context.Context, pid peer.ID, seq uint64) {
+	peerSeq, err := RequestPing(ctx, m.Host, pid, seq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		m.mu.Lock()
+		m.failures[pid]++
+		failures := m.failures[pid]
+		m.mu.Unlock()
+		log.Debug("ping failed", "peer_id", pid.String(), "failures", failures, "err", err)
+		if failures >= maxPingFailures {
+			log.Info("disconnecting unresponsive peer", "peer_id", pid.String())
+			metrics.PeerDisconnects.WithLabelValues("unresponsive").Inc()
+			Disconnect(ctx, m.Host, pid, GoodbyeFaultOrError)
+		}
+		return
+	}
+
+	m.mu.Lock()
+	delete(m.failures, pid)
+	m.mu.Unlock()
+
+	if md, ok := PeerMetadata(m.Host.Peerstore(), pid); ok && md.SeqNumber >= peerSeq {
+		return
+	}
+	md, err := RequestMetadata(ctx, m.Host, pid)
+	if err != nil {
+		log.Debug("metadata request failed", "peer_id", pid.String(), "err", err)
+		return
+	}
+	if err := m.Host.Peerstore().Put(pid, metadataKey, md); err != nil {
+		return
+	}
+	log.Debug("peer metadata updated", "peer_id", pid.String(), "seq", md.SeqNumber, "client", md.Client)
+}
+
+// PeerMetadata returns the metadata last fetched from a peer.
+func PeerMetadata(ps peerstore.Peerstore, pid peer.ID) (*Metadata, bool) {
+	v, err := ps.Get(pid, metadataKey)
+	if err != nil {
+		return nil, false
+	}
+	md, ok := v.(*Metadata)
+	return md, ok
+}
@@ -0,0 +1,71 @@
+package reqresp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/network/reqresp"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPeerMonitorStoresPeerMetadata(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	want := reqresp.Metadata{SeqNumber: 3, Client: "peer/v1"}
+	reqresp.RegisterReqResp(b, &reqresp.ReqRespHandler{
+		Metadata: func() reqresp.Metadata { return want },
+	})
+
+	m := &reqresp.PeerMonitor{
+		Host:     a,
+		Metadata: func() reqresp.Metadata { return reqresp.Metadata{SeqNumber: 1} },
+		Interval: 50 * time.Millisecond,
+	}
+	m.Start(ctx)
+
+	if err := a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	waitFor(t, "peer metadata", func() bool {
+		md, ok := reqresp.PeerMetadata(a.Peerstore(), b.ID())
+		return ok && *md == want
+	})
+}
+
+func TestPeerMonitorDisconnectsUnresponsivePeer(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// b serves no ping protocol, so every ping fails.
+	m := &reqresp.PeerMonitor{
+		Host:     a,
+		Metadata: func() reqresp.Metadata { return reqresp.Metadata{SeqNumber: 1} },
+		Interval: 50 * time.Millisecond,
+	}
+	m.Start(ctx)
+
+	if err := a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	waitFor(t, "unresponsive peer to be disconnected", func() bool {
+		return a.Network().Connectedness(b.ID()) != network.Connected
+	})
+}
@@ -16,4 +16,16 @@ func TestReqRespProtocolIDsMatchCrossClient(t *testing.T) {
 	if reqresp.BlocksByRootProtocolLegacy != "/leanconsensus/req/blocks_by_root/1/ssz_snappy" {
 		t.Fatalf("blocks_by_root legacy protocol mismatch: got %q", reqresp.BlocksByRootProtocolLegacy)
 	}
+	if reqresp.BlocksByRangeProtocol != "/leanconsensus/req/lean_blocks_by_range/1/ssz_snappy" {
+		t.Fatalf("blocks_by_range protocol mismatch: got %q", reqresp.BlocksByRangeProtocol)
+	}
+	if reqresp.GoodbyeProtocol != "/leanconsensus/req/goodbye/1/ssz_snappy" {
+		t.Fatalf("goodbye protocol mismatch: got %q", reqresp.GoodbyeProtocol)
+	}
+	if reqresp.PingProtocol != "/leanconsensus/req/ping/1/ssz_snappy" {
+		t.Fatalf("ping protocol mismatch: got %q", reqresp.PingProtocol)
+	}
+	if reqresp.MetadataProtocol != "/leanconsensus/req/metadata/1/ssz_snappy" {
+		t.Fatalf("metadata protocol mismatch: got %q", reqresp.MetadataProtocol)
+	}
 }
@@ -0,0 +1,206 @@
+package reqresp
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// Quota is a token bucket: up to Burst tokens, refilled at a rate of Burst
+// per Period. A request costs one token, or one per block for block requests.
+type Quota struct {
+	Burst  float64
+	Period time.Duration
+}
+
+// DefaultQuotas are the per-peer quotas of each served protocol. The legacy
+// blocks_by_root protocol shares the bucket of the current one.
+var DefaultQuotas = map[string]Quota{
+	StatusProtocol:        {Burst: 5, Period: 5 * time.Second},
+	PingProtocol:          {Burst: 2, Period: 10 * time.Second},
+	MetadataProtocol:      {Burst: 2, Period: 10 * time.Second},
+	BlocksByRootProtocol:  {Burst: types.MaxRequestBlocks, Period: 10 * time.Second},
+	BlocksByRangeProtocol: {Burst: types.MaxRequestBlocks, Period: 10 * time.Second},
+}
+
+const (
+	// maxStreamsPerPeer is how many requests a peer may have in flight at once.
+	maxStreamsPerPeer = 4
+	// violationPenalty is the score lost per rate limit violation or invalid
+	// request. Sixteen quick violations take a peer to the gossipsub
+	// graylist threshold, after which the peer manager disconnects it.
+	violationPenalty = 1000
+	// penaltyHalfLife is how long it takes a peer's penalty to halve.
+	penaltyHalfLife = time.Minute
+	// limiterPruneInterval is how often idle buckets and spent penalties
+	// are dropped.
+	limiterPruneInterval = time.Minute
+)
+
+// RateLimiter enforces per-peer, per-protocol request quotas and a cap on
+// concurrent requests per peer, and keeps a decaying penalty for peers that
+// exceed them or send invalid requests.
+type RateLimiter struct {
+	Quotas map[string]Quota
+	// NowFn returns the current time; it defaults to time.Now.
+	NowFn func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	streams   map[peer.ID]int
+	penalties map[peer.ID]*penalty
+	lastPrune time.Time
+}
+
+type bucketKey struct {
+	peer     peer.ID
+	protocol string
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type penalty struct {
+	value   float64
+	updated time.Time
+}
+
+// NewRateLimiter returns a limiter using DefaultQuotas.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		Quotas:    DefaultQuotas,
+		buckets:   make(map[bucketKey]*bucket),
+		streams:   make(map[peer.ID]int),
+		penalties: make(map[peer.ID]*penalty),
+	}
+}
+
+// Acquire reserves one of the peer's concurrent request slots. It returns
+// false, and records a violation, if the peer already has maxStreamsPerPeer
+// requests in flight.
+func (l *RateLimiter) Acquire(pid peer.ID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.streams[pid] >= maxStreamsPerPeer {
+		l.penalizeLocked(pid)
+		return false
+	}
+	l.streams[pid]++
+	return true
+}
+
+// Release frees a slot reserved by Acquire.
+func (l *RateLimiter) Release(pid peer.ID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.streams[pid] <= 1 {
+		delete(l.streams, pid)
+		return
+	}
+	l.streams[pid]--
+}
+
+// Allow takes cost tokens from the peer's bucket for protocol. It returns
+// false, and records a violation, if the bucket holds fewer tokens.
+// Protocols without a quota are not limited.
+func (l *RateLimiter) Allow(pid peer.ID, protocol string, cost float64) bool {
+	quota, ok := l.Quotas[protocol]
+	if !ok {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.pruneLocked(now)
+
+	key := bucketKey{pid, protocol}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: quota.Burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.refill(quota, now)
+	if b.tokens < cost {
+		l.penalizeLocked(pid)
+		return false
+	}
+	b.tokens -= cost
+	return true
+}
+
+// Penalize records a violation by the peer, e.g. an invalid request.
+func (l *RateLimiter) Penalize(pid peer.ID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.penalizeLocked(pid)
+}
+
+// Penalty returns the peer's current, decayed penalty as a negative score,
+// suitable for gossipsub.PeerScores.AppSpecific.
+func (l *RateLimiter) Penalty(pid peer.ID) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, ok := l.penalties[pid]
+	if !ok {
+		return 0
+	}
+	p.decay(l.now())
+	return -p.value
+}
+
+func (l *RateLimiter) penalizeLocked(pid peer.ID) {
+	now := l.now()
+	p, ok := l.penalties[pid]
+	if !ok {
+		p = &penalty{updated: now}
+		l.penalties[pid] = p
+	}
+	p.decay(now)
+	p.value += violationPenalty
+}
+
+// pruneLocked drops full buckets and penalties that have decayed away, so
+// peers that left do not accumulate state.
+func (l *RateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < limiterPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		quota := l.Quotas[key.protocol]
+		if b.refill(quota, now); b.tokens >= quota.Burst {
+			delete(l.buckets, key)
+		}
+	}
+	for pid, p := range l.penalties {
+		if p.decay(now); p.value < 1 {
+			delete(l.penalties, pid)
+		}
+	}
+}
+
+func (l *RateLimiter) now() time.Time {
+	if l.NowFn != nil {
+		return l.NowFn()
+	}
+	return time.Now()
+}
+
+func (b *bucket) refill(quota Quota, now time.Time) {
+	elapsed := now.Sub(b.updated)
+	b.updated = now
+	b.tokens = min(quota.Burst, b.tokens+quota.Burst*elapsed.Seconds()/quota.Period.Seconds())
+}
+
+func (p *penalty) decay(now time.Time) {
+	elapsed := now.Sub(p.updated)
+	p.updated = now
+	p.value *= math.Pow(0.5, elapsed.Seconds()/penaltyHalfLife.Seconds())
+}
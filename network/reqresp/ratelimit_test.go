@@ -0,0 +1,100 @@
+package reqresp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/network/reqresp"
+)
+
+func TestRateLimiterRefillsBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := reqresp.NewRateLimiter()
+	l.Quotas = map[string]reqresp.Quota{"p": {Burst: 2, Period: 10 * time.Second}}
+	l.NowFn = func() time.Time { return now }
+	pid := peer.ID("a")
+
+	if !l.Allow(pid, "p", 2) {
+		t.Fatal("first request refused")
+	}
+	if l.Allow(pid, "p", 1) {
+		t.Fatal("request over quota allowed")
+	}
+	now = now.Add(5 * time.Second)
+	if !l.Allow(pid, "p", 1) {
+		t.Fatal("request refused after half a period")
+	}
+	if !l.Allow(peer.ID("b"), "p", 2) {
+		t.Fatal("other peer limited by a's bucket")
+	}
+	if !l.Allow(pid, "unlimited", 100) {
+		t.Fatal("protocol without quota was limited")
+	}
+}
+
+func TestRateLimiterPenaltyDecays(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := reqresp.NewRateLimiter()
+	l.NowFn = func() time.Time { return now }
+	pid := peer.ID("a")
+
+	l.Penalize(pid)
+	l.Penalize(pid)
+	if got := l.Penalty(pid); got != -2000 {
+		t.Fatalf("penalty = %v, want -2000", got)
+	}
+	now = now.Add(time.Minute)
+	if got := l.Penalty(pid); got != -1000 {
+		t.Fatalf("penalty after one half-life = %v, want -1000", got)
+	}
+	if got := l.Penalty(peer.ID("b")); got != 0 {
+		t.Fatalf("penalty of clean peer = %v, want 0", got)
+	}
+}
+
+func TestRateLimiterCapsConcurrentRequests(t *testing.T) {
+	l := reqresp.NewRateLimiter()
+	pid := peer.ID("a")
+	for i := range 4 {
+		if !l.Acquire(pid) {
+			t.Fatalf("Acquire %d refused", i)
+		}
+	}
+	if l.Acquire(pid) {
+		t.Fatal("fifth concurrent request allowed")
+	}
+	l.Release(pid)
+	if !l.Acquire(pid) {
+		t.Fatal("Acquire refused after Release")
+	}
+}
+
+func TestServerRefusesRequestsOverQuota(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx := context.Background()
+
+	limiter := reqresp.NewRateLimiter()
+	limiter.Quotas = map[string]reqresp.Quota{reqresp.StatusProtocol: {Burst: 1, Period: time.Hour}}
+	reqresp.RegisterReqResp(b, &reqresp.ReqRespHandler{
+		OnStatus: func(reqresp.Status) reqresp.Status { return testStatus(0x01) },
+		Limiter:  limiter,
+	})
+	if err := a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	if _, err := reqresp.RequestStatus(ctx, a, b.ID(), testStatus(0x01)); err != nil {
+		t.Fatalf("first RequestStatus: %v", err)
+	}
+	if _, err := reqresp.RequestStatus(ctx, a, b.ID(), testStatus(0x01)); !errors.Is(err, reqresp.ErrResourceUnavailable) {
+		t.Fatalf("second RequestStatus error = %v, want ErrResourceUnavailable", err)
+	}
+	if got := limiter.Penalty(a.ID()); got >= 0 {
+		t.Fatalf("penalty = %v, want < 0", got)
+	}
+}
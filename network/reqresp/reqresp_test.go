@@ -2,6 +2,8 @@ package reqresp_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/geanlabs/gean/network/reqresp"
@@ -96,3 +98,93 @@ func TestReadStatusRejectsInvalidLength(t *testing.T) {
 		}
 	}
 }
+
+func TestBlocksByRangeRequestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	in := reqresp.BlocksByRangeRequest{StartSlot: 17, Count: 64}
+	if err := reqresp.WriteBlocksByRangeRequest(&buf, in); err != nil {
+		t.Fatalf("writeBlocksByRangeRequest: %v", err)
+	}
+
+	out, err := reqresp.ReadBlocksByRangeRequest(&buf)
+	if err != nil {
+		t.Fatalf("readBlocksByRangeRequest: %v", err)
+	}
+	if out != in {
+		t.Fatalf("request mismatch: got %+v, want %+v", out, in)
+	}
+}
+
+func TestReadBlocksByRangeRequestRejectsInvalidCount(t *testing.T) {
+	for _, count := range []uint64{0, types.MaxRequestBlocks + 1} {
+		var buf bytes.Buffer
+		if err := reqresp.WriteBlocksByRangeRequest(&buf, reqresp.BlocksByRangeRequest{Count: count}); err != nil {
+			t.Fatalf("writeBlocksByRangeRequest: %v", err)
+		}
+		if _, err := reqresp.ReadBlocksByRangeRequest(&buf); err == nil {
+			t.Fatalf("expected error for count %d", count)
+		}
+	}
+}
+
+func TestGoodbyeRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := reqresp.WriteGoodbye(&buf, reqresp.GoodbyeTooManyPeers); err != nil {
+		t.Fatalf("WriteGoodbye: %v", err)
+	}
+	reason, err := reqresp.ReadGoodbye(&buf)
+	if err != nil {
+		t.Fatalf("ReadGoodbye: %v", err)
+	}
+	if reason != reqresp.GoodbyeTooManyPeers {
+		t.Fatalf("reason = %d, want %d", reason, reqresp.GoodbyeTooManyPeers)
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	in := reqresp.Metadata{SeqNumber: 7, Attnets: 0b101, Client: "gean/v0.1.0"}
+
+	var buf bytes.Buffer
+	if err := reqresp.WriteMetadata(&buf, in); err != nil {
+		t.Fatalf("WriteMetadata: %v", err)
+	}
+	out, err := reqresp.ReadMetadata(&buf)
+	if err != nil {
+		t.Fatalf("ReadMetadata: %v", err)
+	}
+	if out != in {
+		t.Fatalf("metadata = %+v, want %+v", out, in)
+	}
+}
+
+func TestWriteMetadataRejectsLongClient(t *testing.T) {
+	md := reqresp.Metadata{Client: string(make([]byte, reqresp.MaxClientLength+1))}
+	if err := reqresp.WriteMetadata(&bytes.Buffer{}, md); err == nil {
+		t.Fatal("expected error for oversized client name")
+	}
+}
+
+func TestReadResponseReturnsTypedError(t *testing.T) {
+	var buf bytes.Buffer
+	if err := reqresp.WriteErrorResponse(&buf, reqresp.ResponseInvalidRequest, "bad count"); err != nil {
+		t.Fatalf("WriteErrorResponse: %v", err)
+	}
+
+	err := reqresp.ReadResponse(&buf)
+	if !errors.Is(err, reqresp.ErrInvalidRequest) {
+		t.Fatalf("ReadResponse error = %v, want ErrInvalidRequest", err)
+	}
+	var respErr *reqresp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Message != "bad count" {
+		t.Fatalf("ReadResponse error = %#v, want message %q", err, "bad count")
+	}
+}
+
+func TestReadResponseSuccessAndEOF(t *testing.T) {
+	if err := reqresp.ReadResponse(bytes.NewReader([]byte{reqresp.ResponseSuccess})); err != nil {
+		t.Fatalf("ReadResponse(success) = %v, want nil", err)
+	}
+	if err := reqresp.ReadResponse(bytes.NewReader(nil)); err != io.EOF {
+		t.Fatalf("ReadResponse(empty) = %v, want io.EOF", err)
+	}
+}
@@ -1,31 +1,85 @@
 package reqresp
 
 import (
+	"time"
+
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
+
+	"github.com/geanlabs/gean/observability/metrics"
 )
 
-// RegisterReqResp registers request/response protocol handlers.
+// RegisterReqResp registers request/response protocol handlers. Requests
+// are rate limited per peer when handler.Limiter is set.
 func RegisterReqResp(h host.Host, handler *ReqRespHandler) {
-	h.SetStreamHandler(StatusProtocol, func(s network.Stream) {
+	h.SetStreamHandler(StatusProtocol, serve(handler, handleStatus))
+
+	bbr := serve(handler, handleBlocksByRoot)
+	h.SetStreamHandler(BlocksByRootProtocol, bbr)
+	h.SetStreamHandler(BlocksByRootProtocolLegacy, bbr)
+
+	h.SetStreamHandler(BlocksByRangeProtocol, serve(handler, handleBlocksByRange))
+	h.SetStreamHandler(PingProtocol, serve(handler, handlePing))
+	h.SetStreamHandler(MetadataProtocol, serve(handler, handleMetadata))
+
+	// Goodbye is not limited: it is the last thing a peer sends.
+	h.SetStreamHandler(GoodbyeProtocol, func(s network.Stream) {
 		defer s.Close()
-		handleStatus(s, handler)
+		handleGoodbye(s, handler)
 	})
+}
 
-	bbr := func(s network.Stream) {
+// serve wraps a protocol handler with read and write deadlines and the
+// per-peer concurrent request cap.
+func serve(handler *ReqRespHandler, handle func(network.Stream, *ReqRespHandler)) network.StreamHandler {
+	return func(s network.Stream) {
 		defer s.Close()
-		handleBlocksByRoot(s, handler)
+		s.SetReadDeadline(time.Now().Add(chunkTimeout))
+		s.SetWriteDeadline(time.Now().Add(reqRespTimeout))
+		if l := handler.Limiter; l != nil {
+			pid := s.Conn().RemotePeer()
+			if !l.Acquire(pid) {
+				metrics.ReqRespRateLimited.WithLabelValues(string(s.Protocol())).Inc()
+				WriteErrorResponse(s, ResponseResourceUnavailable, "too many concurrent requests")
+				return
+			}
+			defer l.Release(pid)
+		}
+		handle(s, handler)
 	}
-	h.SetStreamHandler(BlocksByRootProtocol, bbr)
-	h.SetStreamHandler(BlocksByRootProtocolLegacy, bbr)
+}
+
+// allow charges a request against the peer's quota for protocol and
+// answers with ResponseResourceUnavailable if it is exhausted.
+func allow(s network.Stream, handler *ReqRespHandler, protocol string, cost int) bool {
+	if handler.Limiter == nil || handler.Limiter.Allow(s.Conn().RemotePeer(), protocol, float64(cost)) {
+		return true
+	}
+	metrics.ReqRespRateLimited.WithLabelValues(protocol).Inc()
+	WriteErrorResponse(s, ResponseResourceUnavailable, "rate limited")
+	return false
+}
+
+// rejectInvalid answers a malformed request with ResponseInvalidRequest and
+// records it against the peer.
+func rejectInvalid(s network.Stream, handler *ReqRespHandler, err error) {
+	if handler.Limiter != nil {
+		handler.Limiter.Penalize(s.Conn().RemotePeer())
+	}
+	WriteErrorResponse(s, ResponseInvalidRequest, err.Error())
 }
 
 func handleStatus(s network.Stream, handler *ReqRespHandler) {
 	if handler.OnStatus == nil {
+		WriteErrorResponse(s, ResponseServerError, "not supported")
 		return
 	}
 	req, err := ReadStatus(s)
 	if err != nil {
+		rejectInvalid(s, handler, err)
+		return
+	}
+	if !allow(s, handler, StatusProtocol, 1) {
 		return
 	}
 	resp := handler.OnStatus(req)
@@ -39,19 +93,92 @@ func handleStatus(s network.Stream, handler *ReqRespHandler) {
 
 func handleBlocksByRoot(s network.Stream, handler *ReqRespHandler) {
 	if handler.OnBlocksByRoot == nil {
+		WriteErrorResponse(s, ResponseServerError, "not supported")
 		return
 	}
 	roots, err := readBlocksByRootRequest(s)
 	if err != nil {
+		rejectInvalid(s, handler, err)
+		return
+	}
+	if !allow(s, handler, BlocksByRootProtocol, len(roots)) {
 		return
 	}
 	blocks := handler.OnBlocksByRoot(roots)
 	for _, block := range blocks {
-		if _, err := s.Write([]byte{ResponseSuccess}); err != nil {
+		if err := writeSignedBlock(s, block); err != nil {
 			return
 		}
+	}
+}
+
+func handleBlocksByRange(s network.Stream, handler *ReqRespHandler) {
+	if handler.OnBlocksByRange == nil {
+		WriteErrorResponse(s, ResponseServerError, "not supported")
+		return
+	}
+	req, err := ReadBlocksByRangeRequest(s)
+	if err != nil {
+		rejectInvalid(s, handler, err)
+		return
+	}
+	if !allow(s, handler, BlocksByRangeProtocol, int(req.Count)) {
+		return
+	}
+	blocks := handler.OnBlocksByRange(req)
+	if uint64(len(blocks)) > req.Count {
+		blocks = blocks[:req.Count]
+	}
+	for _, block := range blocks {
 		if err := writeSignedBlock(s, block); err != nil {
 			return
 		}
 	}
 }
+
+// handleGoodbye records a peer's goodbye. Goodbye has no response; closing
+// the stream tells the sender the reason was read.
+func handleGoodbye(s network.Stream, handler *ReqRespHandler) {
+	reason, err := ReadGoodbye(s)
+	if err != nil {
+		return
+	}
+	metrics.PeerGoodbyes.WithLabelValues("received", GoodbyeReasonString(reason)).Inc()
+	if handler.OnGoodbye != nil {
+		handler.OnGoodbye(s.Conn().RemotePeer(), reason)
+	}
+}
+
+// handlePing answers a ping with our metadata sequence number.
+func handlePing(s network.Stream, handler *ReqRespHandler) {
+	if handler.Metadata == nil {
+		WriteErrorResponse(s, ResponseServerError, "not supported")
+		return
+	}
+	if _, err := ReadPing(s); err != nil {
+		rejectInvalid(s, handler, err)
+		return
+	}
+	if !allow(s, handler, PingProtocol, 1) {
+		return
+	}
+	if _, err := s.Write([]byte{ResponseSuccess}); err != nil {
+		return
+	}
+	WritePing(s, handler.Metadata().SeqNumber)
+}
+
+// handleMetadata answers a metadata request. The request has no body.
+func handleMetadata(s network.Stream, handler *ReqRespHandler) {
+	if handler.Metadata == nil {
+		WriteErrorResponse(s, ResponseServerError, "not supported")
+		return
+	}
+	if !allow(s, handler, MetadataProtocol, 1) {
+		return
+	}
+	if _, err := s.Write([]byte{ResponseSuccess}); err != nil {
+		return
+	}
+	WriteMetadata(s, handler.Metadata())
+}
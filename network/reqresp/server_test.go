@@ -0,0 +1,43 @@
+package reqresp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/internal/testutil"
+	"github.com/geanlabs/gean/network/reqresp"
+)
+
+func TestClientReportsServerErrors(t *testing.T) {
+	a, b := testutil.NewHost(t), testutil.NewHost(t)
+	ctx := context.Background()
+
+	// b serves status but not blocks_by_range.
+	reqresp.RegisterReqResp(b, &reqresp.ReqRespHandler{
+		OnStatus: func(reqresp.Status) reqresp.Status { return testStatus(0x01) },
+	})
+	if err := a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	if _, err := reqresp.RequestBlocksByRange(ctx, a, b.ID(), 0, 8); !errors.Is(err, reqresp.ErrServerError) {
+		t.Fatalf("RequestBlocksByRange error = %v, want ErrServerError", err)
+	}
+
+	// A malformed status request is answered with ErrInvalidRequest.
+	s, err := a.NewStream(ctx, b.ID(), reqresp.StatusProtocol)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	defer s.Close()
+	if err := reqresp.WriteSnappyFrame(s, []byte{1, 2, 3}); err != nil {
+		t.Fatalf("WriteSnappyFrame: %v", err)
+	}
+	s.CloseWrite()
+	if err := reqresp.ReadResponse(s); !errors.Is(err, reqresp.ErrInvalidRequest) {
+		t.Fatalf("ReadResponse error = %v, want ErrInvalidRequest", err)
+	}
+}
@@ -0,0 +1,202 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/chain/sigverify"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+const (
+	// backfillBatchSize is the number of slots requested per blocks_by_range call.
+	backfillBatchSize = 64
+	// backfillRetryDelay is how long backfill waits when no peer can serve a batch.
+	backfillRetryDelay = 5 * time.Second
+)
+
+// backfill downloads the blocks older than the anchor the node started from,
+// walking parent roots from the finalized block down to genesis. Blocks are
+// checked against the block roots recorded in the finalized state and stored
+// without their states; they are history only and never re-executed.
+//
+// It returns once genesis is reached or ctx is cancelled.
+func (n *Node) backfill(ctx context.Context) {
+	status := n.FC.GetStatus()
+	anchorState, ok := n.FC.GetState(status.FinalizedRoot)
+	if !ok {
+		n.log.Warn("backfill skipped: finalized state not available")
+		return
+	}
+	hashes := anchorState.HistoricalBlockHashes
+
+	// Find the oldest block we hold on the finalized chain.
+	oldest, ok := n.FC.GetBlock(status.FinalizedRoot)
+	if !ok {
+		return
+	}
+	for {
+		parent, ok := n.FC.GetBlock(oldest.ParentRoot)
+		if !ok || oldest.Slot == 0 {
+			break
+		}
+		oldest = parent
+	}
+	metrics.BackfillOldestSlot.Set(float64(oldest.Slot))
+	if oldest.Slot == 0 {
+		return
+	}
+
+	expected := oldest.ParentRoot
+	n.log.Info("backfill started", "oldest_slot", oldest.Slot, "parent_root", logging.ShortHash(expected))
+
+	var peerIdx int
+	for {
+		// The expected block is at the highest non-empty slot below the oldest one.
+		expectedSlot := oldest.Slot - 1
+		for expectedSlot > 0 && hashes[expectedSlot] == types.ZeroHash {
+			expectedSlot--
+		}
+		if hashes[expectedSlot] != expected {
+			n.log.Error("backfill stopped: parent root not in finalized history",
+				"slot", oldest.Slot, "parent_root", logging.ShortHash(expected))
+			return
+		}
+
+		peers := n.Host.P2P.Network().Peers()
+		if len(peers) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backfillRetryDelay):
+				continue
+			}
+		}
+		pid := peers[peerIdx%len(peers)]
+
+		start := expectedSlot + 1 - min(backfillBatchSize, expectedSlot+1)
+		blocks, err := n.backfillBatch(ctx, pid, anchorState, start, expectedSlot+1, expected)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			n.log.Debug("backfill batch failed", "peer", pid.String()[:16], "start_slot", start, "err", err)
+			peerIdx++
+			if peerIdx%len(peers) == 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backfillRetryDelay):
+				}
+			}
+			continue
+		}
+
+		oldest = blocks[len(blocks)-1]
+		expected = oldest.ParentRoot
+		metrics.BackfillOldestSlot.Set(float64(oldest.Slot))
+		metrics.BackfillBlocks.Add(float64(len(blocks)))
+		n.log.Info("backfill batch stored", "oldest_slot", oldest.Slot, "blocks", len(blocks))
+
+		if oldest.Slot == 0 {
+			n.log.Info("backfill complete")
+			return
+		}
+	}
+}
+
+// backfillBatch fetches blocks in [start, end) from a peer, checks that they
+// form a chain ending in expected, stores them and returns them newest first.
+// Nothing is stored unless the whole batch links up.
+func (n *Node) backfillBatch(ctx context.Context, pid peer.ID, anchorState *types.State, start, end uint64, expected [32]byte) ([]*types.Block, error) {
+	resp, err := reqresp.RequestBlocksByRange(ctx, n.Host.P2P, pid, start, end-start)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := anchorState.HistoricalBlockHashes
+	var linked []*types.SignedBlockWithAttestation
+	var roots [][32]byte
+	for i := len(resp) - 1; i >= 0; i-- {
+		sb := resp[i]
+		block := sb.Message.Block
+		root, _ := block.HashTreeRoot()
+		if root != expected {
+			return nil, fmt.Errorf("block at slot %d does not link to expected root %x", block.Slot, expected)
+		}
+		if hashes[block.Slot] != root {
+			return nil, fmt.Errorf("block at slot %d is not in finalized history", block.Slot)
+		}
+		linked = append(linked, sb)
+		roots = append(roots, root)
+		expected = block.ParentRoot
+		if block.Slot == 0 {
+			break
+		}
+	}
+
+	if n.verifyBackfill {
+		if err := n.verifyProposerSignatures(anchorState, linked); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every non-empty slot in the batch must have been returned.
+	if len(linked) == 0 {
+		return nil, fmt.Errorf("peer returned no linked blocks")
+	}
+	lowest := linked[len(linked)-1].Message.Block.Slot
+	for slot := start; slot < lowest; slot++ {
+		if hashes[slot] != types.ZeroHash {
+			return nil, fmt.Errorf("peer omitted block at slot %d", slot)
+		}
+	}
+
+	blocks := make([]*types.Block, len(linked))
+	for i, sb := range linked {
+		n.DB.PutSignedBlock(roots[i], sb)
+		n.DB.PutBlock(roots[i], sb.Message.Block)
+		blocks[i] = sb.Message.Block
+	}
+	return blocks, nil
+}
+
+// verifyProposerSignatures checks each block's proposer signature over its
+// attestation. The block root only commits to the block itself, so this is
+// what ties the rest of the envelope to the proposer.
+func (n *Node) verifyProposerSignatures(state *types.State, blocks []*types.SignedBlockWithAttestation) error {
+	var jobs []sigverify.Job
+	var slots []uint64
+	for _, sb := range blocks {
+		block := sb.Message.Block
+		if block.Slot == 0 {
+			continue // genesis is unsigned
+		}
+		att := sb.Message.ProposerAttestation
+		if att == nil || len(sb.Signature) == 0 {
+			return fmt.Errorf("block at slot %d: missing proposer attestation", block.Slot)
+		}
+		if att.ValidatorID != block.ProposerIndex {
+			return fmt.Errorf("block at slot %d: proposer attestation from validator %d, want %d",
+				block.Slot, att.ValidatorID, block.ProposerIndex)
+		}
+		job, err := sigverify.AttestationJob(state, att, sb.Signature[len(sb.Signature)-1])
+		if err != nil {
+			return fmt.Errorf("block at slot %d: %w", block.Slot, err)
+		}
+		jobs = append(jobs, job)
+		slots = append(slots, block.Slot)
+	}
+	for i, err := range n.FC.Verifier.VerifyBatch(jobs) {
+		if err != nil {
+			return fmt.Errorf("block at slot %d: invalid proposer signature: %w", slots[i], err)
+		}
+	}
+	return nil
+}
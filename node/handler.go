@@ -1,7 +1,12 @@
 package node
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/geanlabs/gean/chain/forkchoice"
 	"github.com/geanlabs/gean/network/gossipsub"
@@ -17,11 +22,7 @@ func registerHandlers(n *Node, fc *forkchoice.Store) error {
 	// Register req/resp handlers.
 	reqresp.RegisterReqResp(n.Host.P2P, &reqresp.ReqRespHandler{
 		OnStatus: func(req reqresp.Status) reqresp.Status {
-			status := fc.GetStatus()
-			return reqresp.Status{
-				Finalized: &types.Checkpoint{Root: status.FinalizedRoot, Slot: status.FinalizedSlot},
-				Head:      &types.Checkpoint{Root: status.Head, Slot: status.HeadSlot},
-			}
+			return localStatus(fc)
 		},
 		OnBlocksByRoot: func(roots [][32]byte) []*types.SignedBlockWithAttestation {
 			var blocks []*types.SignedBlockWithAttestation
@@ -32,11 +33,48 @@ func registerHandlers(n *Node, fc *forkchoice.Store) error {
 			}
 			return blocks
 		},
+		OnBlocksByRange: func(req reqresp.BlocksByRangeRequest) []*types.SignedBlockWithAttestation {
+			return fc.GetCanonicalBlocks(req.StartSlot, req.Count)
+		},
+		OnGoodbye: func(pid peer.ID, reason uint64) {
+			n.log.Debug("peer said goodbye", "peer_id", pid.String(), "reason", reqresp.GoodbyeReasonString(reason))
+		},
+		Metadata: n.localMetadata,
+		Limiter:  n.Host.Limiter,
 	})
 
+	// Validate gossip before it is forwarded to the mesh.
+	if err := gossipsub.RegisterValidators(n.Host.PubSub, n.Host.P2P.ID(), n.Topics, &gossipsub.GossipValidator{
+		ValidateBlock: func(from peer.ID, sb *types.SignedBlockWithAttestation) pubsub.ValidationResult {
+			err := fc.ValidateGossipBlock(sb)
+			if errors.Is(err, forkchoice.ErrUnknownParent) {
+				// Not propagated until we can check it, but kept and chased.
+				n.queueOrphan(n.Host.Ctx, sb, from)
+			}
+			return gossipResult(gossipLog, "block", err)
+		},
+		ValidateAttestation: func(sa *types.SignedAttestation) pubsub.ValidationResult {
+			err := fc.ValidateGossipAttestation(sa)
+			if errors.Is(err, forkchoice.ErrUnknownBlock) {
+				// Not propagated, but checked and kept until the block arrives.
+				fc.ProcessAttestation(sa)
+			}
+			return gossipResult(gossipLog, "attestation", err)
+		},
+		ValidateAggregatedAttestation: func(agg *types.AggregatedAttestation) pubsub.ValidationResult {
+			err := fc.ValidateGossipAggregate(agg)
+			if errors.Is(err, forkchoice.ErrUnknownBlock) {
+				fc.ProcessAggregatedAttestation(agg)
+			}
+			return gossipResult(gossipLog, "aggregated attestation", err)
+		},
+	}); err != nil {
+		return fmt.Errorf("register gossip validators: %w", err)
+	}
+
 	// Subscribe to gossip.
 	if err := gossipsub.SubscribeTopics(n.Host.Ctx, n.Topics, &gossipsub.GossipHandler{
-		OnBlock: func(sb *types.SignedBlockWithAttestation) {
+		OnBlock: func(sb *types.SignedBlockWithAttestation, from peer.ID) {
 			block := sb.Message.Block
 			blockRoot, _ := block.HashTreeRoot()
 			gossipLog.Info("received block via gossip",
@@ -44,7 +82,7 @@ func registerHandlers(n *Node, fc *forkchoice.Store) error {
 				"proposer", block.ProposerIndex,
 				"block_root", logging.ShortHash(blockRoot),
 			)
-			if err := fc.ProcessBlock(sb); err != nil {
+			if err := n.processBlock(n.Host.Ctx, sb, from); err != nil {
 				gossipLog.Warn("rejected gossip block",
 					"slot", block.Slot,
 					"err", err,
@@ -67,3 +105,28 @@ func registerHandlers(n *Node, fc *forkchoice.Store) error {
 
 	return nil
 }
+
+// localStatus returns the status message describing our chain.
+func localStatus(fc *forkchoice.Store) reqresp.Status {
+	status := fc.GetStatus()
+	return reqresp.Status{
+		Finalized: &types.Checkpoint{Root: status.FinalizedRoot, Slot: status.FinalizedSlot},
+		Head:      &types.Checkpoint{Root: status.Head, Slot: status.HeadSlot},
+	}
+}
+
+// gossipResult maps a fork choice validation error to a gossip verdict:
+// invalid messages are rejected, penalizing the sender, and messages that
+// are only unusable now are ignored.
+func gossipResult(log *slog.Logger, kind string, err error) pubsub.ValidationResult {
+	switch {
+	case err == nil:
+		return pubsub.ValidationAccept
+	case errors.Is(err, forkchoice.ErrInvalid):
+		log.Debug("rejected gossip "+kind, "err", err)
+		return pubsub.ValidationReject
+	default:
+		log.Debug("ignored gossip "+kind, "err", err)
+		return pubsub.ValidationIgnore
+	}
+}
@@ -0,0 +1,35 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// maintainKeys keeps the prepared window of every validator key ahead of the
+// wall clock, so signing never has to wait for it. It runs once per slot
+// until ctx is cancelled.
+func (n *Node) maintainKeys(ctx context.Context) {
+	if len(n.Keys) == 0 {
+		return
+	}
+	ticker := time.NewTicker(types.SecondsPerSlot * time.Second)
+	defer ticker.Stop()
+	for {
+		slot := uint64(0)
+		if !n.Clock.IsBeforeGenesis() {
+			slot = n.Clock.CurrentSlot()
+		}
+		for _, key := range n.Keys {
+			if err := key.Maintain(slot); err != nil {
+				n.log.Error("xmss key maintenance failed", "validator", key.Index, "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,43 +1,81 @@
 package node
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/geanlabs/gean/api"
+	"github.com/geanlabs/gean/chain/checkpointsync"
+	"github.com/geanlabs/gean/chain/events"
 	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/pending"
+	"github.com/geanlabs/gean/chain/slasher"
 	"github.com/geanlabs/gean/chain/statetransition"
+	"github.com/geanlabs/gean/chain/syncer"
 	"github.com/geanlabs/gean/network"
 	"github.com/geanlabs/gean/network/gossipsub"
 	"github.com/geanlabs/gean/network/p2p"
+	"github.com/geanlabs/gean/network/reqresp"
 	"github.com/geanlabs/gean/observability/logging"
 	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/slashprotection"
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/storage/leveldb"
 	"github.com/geanlabs/gean/storage/memory"
 	"github.com/geanlabs/gean/types"
-	"github.com/geanlabs/gean/xmss/leansig"
+	"github.com/geanlabs/gean/xmss/keymanager"
 )
 
 // New creates and wires up a new Node.
 func New(cfg Config) (*Node, error) {
 	log := logging.NewComponentLogger(logging.CompNode)
 
-	fc := initGenesis(log, cfg)
+	fc, db, err := initForkChoice(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorsRoot, err := types.ValidatorsRoot(cfg.Validators)
+	if err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("validators root: %w", err)
+	}
+	forkDigest := types.ForkDigest(validatorsRoot, cfg.GenesisTime)
+	log.Info("network identity", "fork_digest", fmt.Sprintf("%x", forkDigest), "spec_version", types.SpecVersion)
 
-	host, topics, err := initP2P(cfg)
+	host, topics, err := initP2P(cfg, forkDigest)
 	if err != nil {
+		closeDB(db)
 		return nil, err
 	}
 
-	p2pManager, p2pDiscovery, err2 := initDiscovery(log, cfg)
+	p2pManager, p2pDiscovery, err2 := initDiscovery(log, cfg, forkDigest)
 	if err2 != nil {
 		host.Close()
+		closeDB(db)
 		return nil, err2
 	}
 
-	validatorKeys, err := loadValidatorKeys(log, cfg)
+	managedKeys, err := loadValidatorKeys(log, cfg)
+	if err != nil {
+		if p2pDiscovery != nil {
+			p2pDiscovery.Close()
+		}
+		if p2pManager != nil {
+			p2pManager.Close()
+		}
+		host.Close()
+		closeDB(db)
+		return nil, err
+	}
+
+	protection, err := openSlashingProtection(cfg)
 	if err != nil {
 		if p2pDiscovery != nil {
 			p2pDiscovery.Close()
@@ -46,8 +84,17 @@ func New(cfg Config) (*Node, error) {
 			p2pManager.Close()
 		}
 		host.Close()
+		closeDB(db)
 		return nil, err
 	}
+	if protection != nil {
+		fc.SigningGuard = protection
+	}
+
+	validatorKeys := make(map[uint64]forkchoice.Signer, len(managedKeys))
+	for _, key := range managedKeys {
+		validatorKeys[key.Index] = key
+	}
 
 	validator := &ValidatorDuties{
 		Indices:                      cfg.ValidatorIDs,
@@ -60,17 +107,30 @@ func New(cfg Config) (*Node, error) {
 		Log:                          logging.NewComponentLogger(logging.CompValidator),
 	}
 
+	feed := events.NewFeed()
+	fc.Events = feed
+	fc.Slasher = slasher.New(db, feed)
+
 	n := &Node{
 		FC:           fc,
+		DB:           db,
+		Events:       feed,
 		Host:         host,
 		Topics:       topics,
 		Clock:        NewClock(cfg.GenesisTime),
 		Validator:    validator,
+		Keys:         managedKeys,
+		Protection:   protection,
+		Pending:      pending.NewBlocks(pending.DefaultMaxBlocks),
 		P2PManager:   p2pManager,
 		P2PDiscovery: p2pDiscovery,
 		log:          log,
+
+		verifyBackfill: cfg.BackfillVerifySignatures,
 	}
 
+	fc.FetchBlock = func(root [32]byte) { n.fetchBlock(host.Ctx, root) }
+
 	if err := registerHandlers(n, fc); err != nil {
 		if p2pDiscovery != nil {
 			p2pDiscovery.Close()
@@ -79,6 +139,10 @@ func New(cfg Config) (*Node, error) {
 			p2pManager.Close()
 		}
 		host.Close()
+		closeDB(db)
+		if protection != nil {
+			protection.Close()
+		}
 		return nil, err
 	}
 
@@ -86,12 +150,146 @@ func New(cfg Config) (*Node, error) {
 		network.ConnectBootnodes(host.Ctx, host.P2P, cfg.Bootnodes)
 	}
 
+	n.Handshake = &reqresp.Handshake{
+		Host:   host.P2P,
+		Status: func() reqresp.Status { return localStatus(fc) },
+		Check: func(status reqresp.Status) error {
+			return fc.CheckFinalized(status.Finalized)
+		},
+	}
+	n.Sync = &syncer.Syncer{
+		Host:        host.P2P,
+		FC:          fc,
+		Handshake:   n.Handshake,
+		OnImport:    n.replayPending,
+		Penalize:    host.Limiter.Penalize,
+		CurrentSlot: n.Clock.CurrentSlot,
+	}
+	n.Monitor = &reqresp.PeerMonitor{
+		Host:     host.P2P,
+		Metadata: n.localMetadata,
+	}
+	n.Peers = &network.PeerManager{
+		Host:        host.P2P,
+		TargetPeers: cfg.TargetPeers,
+		MaxPeers:    cfg.MaxPeers,
+		Static:      network.BootnodeAddrInfos(cfg.Bootnodes),
+		Score:       host.Scores.Score,
+	}
+	if p2pDiscovery != nil {
+		n.Peers.Discover = p2pDiscovery.LookupRandom
+	}
+
 	startMetrics(log, cfg)
 
+	if cfg.APIPort > 0 {
+		n.API = &api.Service{
+			FC:          fc,
+			Host:        host.P2P,
+			Events:      feed,
+			CurrentSlot: n.Clock.CurrentSlot,
+			PeerScore:   host.Scores.Score,
+			SyncState:   func() string { return n.Sync.State().String() },
+			Slasher:     fc.Slasher,
+		}
+		if p2pManager != nil {
+			n.API.LocalENR = func() string { return p2pManager.Node().String() }
+		}
+		if err := n.API.Start(cfg.APIPort); err != nil {
+			n.Close()
+			return nil, err
+		}
+	}
+
 	return n, nil
 }
 
-func initGenesis(log *slog.Logger, cfg Config) *forkchoice.Store {
+// initForkChoice opens the configured storage backend and builds the fork
+// choice store, resuming from persisted checkpoints when there are any.
+func initForkChoice(log *slog.Logger, cfg Config) (*forkchoice.Store, storage.Store, error) {
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fc *forkchoice.Store
+	if _, ok := db.GetCheckpoints(); ok {
+		fc, err = forkchoice.RestoreStore(db)
+		if err != nil {
+			closeDB(db)
+			return nil, nil, fmt.Errorf("restore fork choice: %w", err)
+		}
+		if fc.GenesisTime() != cfg.GenesisTime {
+			closeDB(db)
+			return nil, nil, fmt.Errorf("database genesis time %d does not match config genesis time %d",
+				fc.GenesisTime(), cfg.GenesisTime)
+		}
+		status := fc.GetStatus()
+		log.Info("resumed from database",
+			"head_slot", status.HeadSlot,
+			"head_root", logging.ShortHash(status.Head),
+			"justified_slot", status.JustifiedSlot,
+			"finalized_slot", status.FinalizedSlot,
+		)
+	} else if cfg.CheckpointSyncURL != "" || cfg.CheckpointStatePath != "" {
+		fc, err = initCheckpoint(log, cfg, db)
+		if err != nil {
+			closeDB(db)
+			return nil, nil, err
+		}
+	} else {
+		fc = initGenesis(log, cfg, db)
+	}
+
+	fc.NowFn = func() uint64 { return uint64(time.Now().Unix()) }
+	return fc, db, nil
+}
+
+func openDB(cfg Config) (storage.Store, error) {
+	switch cfg.DB {
+	case "", "memory":
+		return memory.New(), nil
+	case "leveldb":
+		path := filepath.Join(cfg.DataDir, "db")
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create db dir: %w", err)
+		}
+		db, err := leveldb.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown db backend %q (want memory or leveldb)", cfg.DB)
+	}
+}
+
+// openSlashingProtection opens the slashing-protection database under
+// <data-dir>/slashing-protection. Nodes without validators don't need one.
+func openSlashingProtection(cfg Config) (*slashprotection.DB, error) {
+	if len(cfg.ValidatorIDs) == 0 {
+		return nil, nil
+	}
+	db, err := slashprotection.Open(SlashingProtectionPath(cfg.DataDir))
+	if err != nil {
+		return nil, fmt.Errorf("open slashing protection: %w", err)
+	}
+	return db, nil
+}
+
+// SlashingProtectionPath returns the slashing-protection database path for a data directory.
+func SlashingProtectionPath(dataDir string) string {
+	return filepath.Join(dataDir, "slashing-protection")
+}
+
+// closeDB closes storage backends that hold resources such as open files.
+func closeDB(db storage.Store) {
+	if c, ok := db.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func initGenesis(log *slog.Logger, cfg Config, db storage.Store) *forkchoice.Store {
 	genesisState := statetransition.GenerateGenesis(cfg.GenesisTime, cfg.Validators)
 
 	genesisBlock := &types.Block{
@@ -111,12 +309,43 @@ func initGenesis(log *slog.Logger, cfg Config) *forkchoice.Store {
 		"block_root", logging.ShortHash(genesisRoot),
 	)
 
-	fc := forkchoice.NewStore(genesisState, genesisBlock, memory.New())
-	fc.NowFn = func() uint64 { return uint64(time.Now().Unix()) }
-	return fc
+	return forkchoice.NewStore(genesisState, genesisBlock, db)
 }
 
-func initP2P(cfg Config) (*network.Host, *gossipsub.Topics, error) {
+// initCheckpoint starts the fork choice from a trusted finalized state and
+// its anchor block, loaded from files or fetched from another node.
+func initCheckpoint(log *slog.Logger, cfg Config, db storage.Store) (*forkchoice.Store, error) {
+	var anchor *checkpointsync.Anchor
+	var err error
+	if cfg.CheckpointSyncURL != "" {
+		log.Info("fetching checkpoint", "url", cfg.CheckpointSyncURL)
+		anchor, err = checkpointsync.Fetch(context.Background(), cfg.CheckpointSyncURL)
+	} else {
+		anchor, err = checkpointsync.ReadFiles(cfg.CheckpointStatePath, cfg.CheckpointBlockPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	}
+	if err := anchor.Verify(cfg.GenesisTime, cfg.Validators); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+
+	fc := forkchoice.NewStore(anchor.State, anchor.Block.Message.Block, db)
+	// NewStore only knows the bare block; keep the signed envelope so the
+	// anchor can be served to peers.
+	root := anchor.Root()
+	db.PutSignedBlock(root, anchor.Block)
+
+	log.Info("checkpoint state initialized",
+		"slot", anchor.State.Slot,
+		"block_root", logging.ShortHash(root),
+	)
+	return fc, nil
+}
+
+// initP2P starts the libp2p host and joins the gossip topics. Topics are
+// named by the fork digest unless cfg.DevnetID overrides it.
+func initP2P(cfg Config, forkDigest [4]byte) (*network.Host, *gossipsub.Topics, error) {
 	host, err := network.NewHost(cfg.ListenAddr, cfg.NodeKeyPath, cfg.Bootnodes)
 	if err != nil {
 		return nil, nil, fmt.Errorf("create host: %w", err)
@@ -130,13 +359,17 @@ func initP2P(cfg Config) (*network.Host, *gossipsub.Topics, error) {
 
 	devnetID := cfg.DevnetID
 	if devnetID == "" {
-		devnetID = "devnet0"
+		devnetID = fmt.Sprintf("%x", forkDigest)
 	}
 	topics, err := gossipsub.JoinTopics(host.PubSub, devnetID)
 	if err != nil {
 		host.Close()
 		return nil, nil, fmt.Errorf("join topics: %w", err)
 	}
+	if err := topics.SetScoreParams(len(cfg.Validators)); err != nil {
+		host.Close()
+		return nil, nil, fmt.Errorf("topic score params: %w", err)
+	}
 
 	gossipLog := logging.NewComponentLogger(logging.CompGossip)
 	gossipLog.Info("gossipsub topics joined", "devnet", devnetID)
@@ -144,7 +377,7 @@ func initP2P(cfg Config) (*network.Host, *gossipsub.Topics, error) {
 	return host, topics, nil
 }
 
-func initDiscovery(log *slog.Logger, cfg Config) (*p2p.LocalNodeManager, *p2p.DiscoveryService, error) {
+func initDiscovery(log *slog.Logger, cfg Config, forkDigest [4]byte) (*p2p.LocalNodeManager, *p2p.DiscoveryService, error) {
 	discPort := cfg.DiscoveryPort
 	if discPort == 0 {
 		discPort = 9000
@@ -159,6 +392,8 @@ func initDiscovery(log *slog.Logger, cfg Config) (*p2p.LocalNodeManager, *p2p.Di
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to init p2p manager: %w", err)
 	}
+	p2pManager.SetForkDigest(forkDigest)
+	p2pManager.SetClient("gean", Version)
 
 	p2pDiscovery, err := p2p.NewDiscoveryService(p2pManager, discPort, cfg.Bootnodes)
 	if err != nil {
@@ -168,25 +403,23 @@ func initDiscovery(log *slog.Logger, cfg Config) (*p2p.LocalNodeManager, *p2p.Di
 	return p2pManager, p2pDiscovery, nil
 }
 
-func loadValidatorKeys(log *slog.Logger, cfg Config) (map[uint64]forkchoice.Signer, error) {
-	keys := make(map[uint64]forkchoice.Signer)
+func loadValidatorKeys(log *slog.Logger, cfg Config) ([]*keymanager.ManagedKey, error) {
 	if cfg.ValidatorKeysDir == "" {
 		if len(cfg.ValidatorIDs) > 0 {
 			log.Warn("no validator keys directory specified; validator duties will fail signing")
 		}
-		return keys, nil
+		return nil, nil
 	}
 
+	var keys []*keymanager.ManagedKey
 	for _, idx := range cfg.ValidatorIDs {
-		pkPath := filepath.Join(cfg.ValidatorKeysDir, fmt.Sprintf("validator_%d_pk.ssz", idx))
-		skPath := filepath.Join(cfg.ValidatorKeysDir, fmt.Sprintf("validator_%d_sk.ssz", idx))
-
-		kp, err := leansig.LoadKeypair(pkPath, skPath)
+		key, err := keymanager.Load(cfg.ValidatorKeysDir, idx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load keypair for validator %d: %w", idx, err)
 		}
-		keys[idx] = kp
-		log.Info("loaded validator keypair", "validator_index", idx)
+		keys = append(keys, key)
+		lastEpoch, signed := key.LastEpoch()
+		log.Info("loaded validator keypair", "validator_index", idx, "last_epoch", lastEpoch, "has_signed", signed)
 	}
 	return keys, nil
 }
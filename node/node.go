@@ -3,23 +3,49 @@ package node
 import (
 	"context"
 	"log/slog"
+	"sync"
 
+	"github.com/geanlabs/gean/api"
+	"github.com/geanlabs/gean/chain/events"
 	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/chain/pending"
+	"github.com/geanlabs/gean/chain/syncer"
 	"github.com/geanlabs/gean/network"
 	"github.com/geanlabs/gean/network/gossipsub"
 	"github.com/geanlabs/gean/network/p2p"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/slashprotection"
+	"github.com/geanlabs/gean/storage"
 	"github.com/geanlabs/gean/types"
+	"github.com/geanlabs/gean/xmss/keymanager"
 )
 
 var Version = "v0.1.0"
 
 // Node is the main gean node orchestrator.
 type Node struct {
-	FC     *forkchoice.Store
-	Host   *network.Host
-	Topics *gossipsub.Topics
-	// API       *api.Service // Temporary disable until found
+	FC        *forkchoice.Store
+	DB        storage.Store
+	Events    *events.Feed
+	Host      *network.Host
+	Topics    *gossipsub.Topics
+	API       *api.Service
 	Validator *ValidatorDuties
+	// Keys are the XMSS keys behind Validator.Keys.
+	Keys []*keymanager.ManagedKey
+	// Protection is the slashing-protection database; nil without validators.
+	Protection *slashprotection.DB
+
+	// Peers keeps the node connected; started by Run.
+	Peers *network.PeerManager
+	// Handshake exchanges status with new peers; started by Run.
+	Handshake *reqresp.Handshake
+	// Sync tracks peer statuses and range syncs towards them; started by Run.
+	Sync *syncer.Syncer
+	// Pending holds blocks waiting for an unknown parent.
+	Pending *pending.Blocks
+	// Monitor pings peers and tracks their metadata; started by Run.
+	Monitor *reqresp.PeerMonitor
 
 	// P2P Services
 	P2PManager   *p2p.LocalNodeManager
@@ -28,12 +54,20 @@ type Node struct {
 	Clock *Clock
 	log   *slog.Logger
 
+	// verifyBackfill enables proposer signature checks on backfilled blocks.
+	verifyBackfill bool
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
 func (n *Node) Close() {
-	n.cancel()
+	if n.cancel != nil {
+		n.cancel()
+	}
+	if n.API != nil {
+		n.API.Close()
+	}
 	if n.P2PDiscovery != nil {
 		n.P2PDiscovery.Close()
 	}
@@ -41,8 +75,38 @@ func (n *Node) Close() {
 		n.P2PManager.Close()
 	}
 	if n.Host != nil {
+		n.sayGoodbye()
 		n.Host.Close()
 	}
+	if n.DB != nil {
+		closeDB(n.DB)
+	}
+	if n.Protection != nil {
+		n.Protection.Close()
+	}
+}
+
+// localMetadata returns the metadata served to peers. The sequence number
+// follows our ENR; without discovery there is no ENR and it stays at 1.
+func (n *Node) localMetadata() reqresp.Metadata {
+	seq := uint64(1)
+	if n.P2PManager != nil {
+		seq = n.P2PManager.Seq()
+	}
+	return reqresp.Metadata{SeqNumber: seq, Client: "gean/" + Version}
+}
+
+// sayGoodbye tells every connected peer we are shutting down.
+func (n *Node) sayGoodbye() {
+	var wg sync.WaitGroup
+	for _, pid := range n.Host.P2P.Network().Peers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reqresp.SendGoodbye(context.Background(), n.Host.P2P, pid, reqresp.GoodbyeClientShutdown)
+		}()
+	}
+	wg.Wait()
 }
 
 // Config holds node configuration.
@@ -57,5 +121,18 @@ type Config struct {
 	ValidatorIDs     []uint64
 	ValidatorKeysDir string
 	MetricsPort      int
-	DevnetID         string
+	APIPort          int
+	DevnetID         string // gossip topic network name; empty uses the fork digest
+	DB               string // storage backend: "memory" or "leveldb"
+	TargetPeers      int    // peer count the peer manager dials up to
+	MaxPeers         int    // peer count above which peers are disconnected
+
+	// Checkpoint sync: start from a trusted finalized state instead of
+	// genesis. CheckpointSyncURL takes precedence over the file paths.
+	CheckpointSyncURL   string
+	CheckpointStatePath string
+	CheckpointBlockPath string
+	// BackfillVerifySignatures checks proposer signatures of historical
+	// blocks downloaded behind a checkpoint anchor.
+	BackfillVerifySignatures bool
 }
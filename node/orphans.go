@@ -0,0 +1,141 @@
+package node
+
+import (
+	"context"
+	"errors"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/geanlabs/gean/chain/forkchoice"
+	"github.com/geanlabs/gean/network/reqresp"
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/types"
+)
+
+const (
+	// maxSyncDepth is how many missing ancestors of a pending block are
+	// fetched by root. Deeper gaps are closed by range sync.
+	maxSyncDepth = 64
+	// maxBlockFetchPeers is how many peers fetchBlock asks for a block.
+	maxBlockFetchPeers = 3
+)
+
+// processBlock imports a block, queues it if its parent is unknown, and
+// replays any queued descendants once it is imported.
+func (n *Node) processBlock(ctx context.Context, sb *types.SignedBlockWithAttestation, from peer.ID) error {
+	err := n.FC.ProcessBlock(sb)
+	if errors.Is(err, forkchoice.ErrUnknownParent) {
+		n.queueOrphan(ctx, sb, from)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	root, _ := sb.Message.Block.HashTreeRoot()
+	n.replayPending(root)
+	return nil
+}
+
+// queueOrphan adds a block with an unknown parent to the pending pool and
+// fetches its missing ancestors from the peer that sent it.
+func (n *Node) queueOrphan(ctx context.Context, sb *types.SignedBlockWithAttestation, from peer.ID) {
+	root, _ := sb.Message.Block.HashTreeRoot()
+	if !n.Pending.Add(sb, root, from) {
+		return
+	}
+	n.log.Debug("queued block with unknown parent",
+		"slot", sb.Message.Block.Slot,
+		"block_root", logging.ShortHash(root),
+		"parent_root", logging.ShortHash(sb.Message.Block.ParentRoot),
+	)
+	go n.fetchAncestors(ctx, root)
+}
+
+// fetchAncestors requests the missing ancestors of a pending block one at a
+// time, newest first, until one connects to a block we hold. The queued
+// descendants are then replayed in order. It gives up after maxSyncDepth
+// blocks.
+func (n *Node) fetchAncestors(ctx context.Context, root [32]byte) {
+	for range maxSyncDepth {
+		missing, from := n.Pending.MissingAncestor(root)
+		if _, ok := n.FC.GetBlock(missing); ok {
+			n.replayPending(missing)
+			return
+		}
+		if from == "" {
+			return
+		}
+
+		blocks, err := reqresp.RequestBlocksByRoot(ctx, n.Host.P2P, from, [][32]byte{missing})
+		if err != nil || len(blocks) == 0 {
+			metrics.OrphanParentRequests.WithLabelValues("failure").Inc()
+			n.log.Debug("parent request failed", "peer", from.String()[:16], "root", logging.ShortHash(missing), "err", err)
+			return
+		}
+		sb := blocks[0]
+		if got, _ := sb.Message.Block.HashTreeRoot(); got != missing {
+			metrics.OrphanParentRequests.WithLabelValues("failure").Inc()
+			return
+		}
+		metrics.OrphanParentRequests.WithLabelValues("success").Inc()
+
+		// The parent may connect straight away; otherwise queue it and keep walking.
+		err = n.FC.ProcessBlock(sb)
+		switch {
+		case err == nil:
+			n.replayPending(missing)
+			return
+		case !errors.Is(err, forkchoice.ErrUnknownParent):
+			n.log.Debug("fetched parent rejected", "slot", sb.Message.Block.Slot, "err", err)
+			return
+		case !n.Pending.Add(sb, missing, from):
+			return
+		}
+	}
+}
+
+// fetchBlock requests a block that deferred attestations reference from up
+// to maxBlockFetchPeers connected peers in turn, and imports the first copy
+// received. Its ancestors are chased like those of a gossip orphan.
+func (n *Node) fetchBlock(ctx context.Context, root [32]byte) {
+	if _, ok := n.FC.GetBlock(root); ok || n.Pending.Has(root) {
+		return
+	}
+	for i, pid := range n.Host.P2P.Network().Peers() {
+		if i == maxBlockFetchPeers {
+			break
+		}
+		blocks, err := reqresp.RequestBlocksByRoot(ctx, n.Host.P2P, pid, [][32]byte{root})
+		if err != nil || len(blocks) == 0 {
+			continue
+		}
+		sb := blocks[0]
+		if got, _ := sb.Message.Block.HashTreeRoot(); got != root {
+			continue
+		}
+		if err := n.processBlock(ctx, sb, pid); err != nil && !errors.Is(err, forkchoice.ErrUnknownParent) {
+			n.log.Debug("fetched block rejected", "slot", sb.Message.Block.Slot, "err", err)
+		}
+		return
+	}
+}
+
+// replayPending imports the queued descendants of root, parents before
+// children.
+func (n *Node) replayPending(root [32]byte) {
+	queue := [][32]byte{root}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, sb := range n.Pending.TakeChildren(parent) {
+			if err := n.FC.ProcessBlock(sb); err != nil {
+				n.log.Debug("pending block rejected", "slot", sb.Message.Block.Slot, "err", err)
+				continue
+			}
+			childRoot, _ := sb.Message.Block.HashTreeRoot()
+			n.log.Info("imported pending block", "slot", sb.Message.Block.Slot, "block_root", logging.ShortHash(childRoot))
+			queue = append(queue, childRoot)
+		}
+	}
+}
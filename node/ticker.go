@@ -16,8 +16,20 @@ func (n *Node) Run(ctx context.Context) error {
 		"peers", len(n.Host.P2P.Network().Peers()),
 	)
 
-	// Attempt initial sync with connected peers.
-	n.initialSync(ctx)
+	// Exchange status with every new peer, sync towards what peers report,
+	// and keep the peer count up.
+	n.Sync.Start(ctx)
+	n.Handshake.OnStatus = n.Sync.SetPeerStatus
+	n.Handshake.Start(ctx)
+	n.Peers.Start(ctx)
+	// Ping peers to catch dead connections and refresh their metadata.
+	n.Monitor.Start(ctx)
+
+	// Keep XMSS keys prepared ahead of the slots they will sign.
+	go n.maintainKeys(ctx)
+
+	// Fetch history behind a checkpoint anchor; a no-op after genesis start.
+	go n.backfill(ctx)
 
 	ticker := n.Clock.SlotTicker()
 	defer ticker.Stop()
@@ -27,9 +39,7 @@ func (n *Node) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			n.log.Info("node shutting down")
-			if err := n.Host.Close(); err != nil {
-				n.log.Warn("host close error", "err", err)
-			}
+			n.Close()
 			return nil
 		case <-ticker.C:
 			if n.Clock.IsBeforeGenesis() {
@@ -44,16 +54,6 @@ func (n *Node) Run(ctx context.Context) error {
 
 			status := n.FC.GetStatus()
 
-			// Sync before duties: if head is behind, try catching up.
-			if slot > status.HeadSlot+2 {
-				for _, pid := range n.Host.P2P.Network().Peers() {
-					if n.syncWithPeer(ctx, pid) {
-						status = n.FC.GetStatus() // refresh after sync
-						break
-					}
-				}
-			}
-
 			// Execute validator duties only when synced.
 			if slot <= status.HeadSlot+2 {
 				n.Validator.OnInterval(ctx, slot, interval)
@@ -65,6 +65,8 @@ func (n *Node) Run(ctx context.Context) error {
 				// Refresh status for metrics if not already current.
 				status = n.FC.GetStatus()
 
+				n.Pending.Prune(slot, status.FinalizedSlot)
+
 				metrics.CurrentSlot.Set(float64(slot))
 				metrics.HeadSlot.Set(float64(status.HeadSlot))
 				metrics.LatestFinalizedSlot.Set(float64(status.FinalizedSlot))
@@ -78,6 +80,7 @@ func (n *Node) Run(ctx context.Context) error {
 					"finalized", status.FinalizedSlot,
 					"justified", status.JustifiedSlot,
 					"peers", peerCount,
+					"sync", n.Sync.State().String(),
 					"elapsed", logging.TimeSince(start),
 				)
 				lastSlot = slot
@@ -16,10 +16,14 @@ const (
 	CompValidator  = "validator"
 	CompConsensus  = "consensus"
 	CompForkChoice = "forkchoice"
+	CompSlasher    = "slasher"
 	CompNetwork    = "network"
 	CompGossip     = "gossip"
+	CompSync       = "sync"
 	CompReqResp    = "reqresp"
 	CompMetrics    = "metrics"
+	CompStorage    = "storage"
+	CompAPI        = "api"
 )
 
 // ANSI color codes.
@@ -60,12 +60,47 @@ var AttestationsInvalid = prometheus.NewCounter(prometheus.CounterOpts{
 	Help: "Total number of invalid attestations",
 })
 
+var AttestationsDeferred = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_attestations_deferred_total",
+	Help: "Total number of attestations deferred until an unknown block they reference is imported",
+})
+
+var AttestationsResolved = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_attestations_resolved_total",
+	Help: "Total number of deferred attestations replayed after their blocks were imported",
+})
+
+var AttestationsExpired = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_attestations_expired_total",
+	Help: "Total number of deferred attestations dropped before their blocks were imported",
+})
+
 var AttestationValidationTime = prometheus.NewHistogram(prometheus.HistogramOpts{
 	Name:    "lean_attestation_validation_time_seconds",
 	Help:    "Time taken to validate attestation",
 	Buckets: fastBuckets,
 })
 
+var ForkChoicePrunedBlocks = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_fork_choice_pruned_blocks_total",
+	Help: "Total number of blocks pruned after finalization",
+})
+
+var ForkChoicePrunedStates = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_fork_choice_pruned_states_total",
+	Help: "Total number of states pruned after finalization",
+})
+
+var ForkChoicePrunedAttestations = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_fork_choice_pruned_attestations_total",
+	Help: "Total number of stale attestations pruned after finalization",
+})
+
+var SlashingsDetected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_slashings_detected_total",
+	Help: "Total number of validator equivocations detected, by kind",
+}, []string{"kind"})
+
 // --- State Transition ---
 
 var LatestJustifiedSlot = prometheus.NewGauge(prometheus.GaugeOpts{
@@ -119,6 +154,11 @@ var ValidatorsCount = prometheus.NewGauge(prometheus.GaugeOpts{
 	Help: "Number of validators managed by a node",
 })
 
+var XMSSKeyEpochsRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "lean_xmss_key_epochs_remaining",
+	Help: "Epochs left before a validator's XMSS key reaches the end of its activation",
+}, []string{"validator"})
+
 // --- Network ---
 
 var ConnectedPeers = prometheus.NewGauge(prometheus.GaugeOpts{
@@ -126,6 +166,73 @@ var ConnectedPeers = prometheus.NewGauge(prometheus.GaugeOpts{
 	Help: "Number of connected peers",
 })
 
+var PeerDials = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_peer_dials_total",
+	Help: "Total number of outbound dials by the peer manager, by source and result",
+}, []string{"source", "result"})
+
+var PeerDisconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_peer_disconnects_total",
+	Help: "Total number of peers disconnected by the node, by reason",
+}, []string{"reason"})
+
+var PeerGoodbyes = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_peer_goodbyes_total",
+	Help: "Total number of goodbye messages, by direction and reason",
+}, []string{"direction", "reason"})
+
+var ReqRespRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_reqresp_rate_limited_total",
+	Help: "Total number of req/resp requests refused by the rate limiter, by protocol",
+}, []string{"protocol"})
+
+var GossipPeerScore = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "lean_gossip_peer_score",
+	Help: "Gossipsub score of each connected peer",
+}, []string{"peer"})
+
+var GossipPeersByScore = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "lean_gossip_peers_by_score",
+	Help: "Number of peers scored by gossipsub, by score band: graylisted, publish_blocked, gossip_blocked, negative or positive",
+}, []string{"band"})
+
+var GossipValidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_gossip_validations_total",
+	Help: "Total number of gossip messages validated, by topic and result",
+}, []string{"topic", "result"})
+
+// --- Sync ---
+
+var BackfillOldestSlot = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "lean_backfill_oldest_slot",
+	Help: "Slot of the oldest block held after backfill (0 when complete)",
+})
+
+var BackfillBlocks = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_backfill_blocks_total",
+	Help: "Total number of historical blocks stored by backfill",
+})
+
+var PendingBlocks = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "lean_pending_blocks",
+	Help: "Number of blocks waiting for an unknown parent",
+})
+
+var OrphanParentRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_orphan_parent_requests_total",
+	Help: "Total number of blocks_by_root requests for missing parents, by result",
+}, []string{"result"})
+
+var SyncState = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "lean_sync_state",
+	Help: "Sync state of the node: 0 idle, 1 syncing, 2 synced",
+})
+
+var SyncBatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "lean_sync_batches_total",
+	Help: "Total number of range sync batch downloads, by result",
+}, []string{"result"})
+
 // --- Devnet-1 Baseline Metrics ---
 
 var SignatureVerificationTime = prometheus.NewHistogram(prometheus.HistogramOpts{
@@ -134,6 +241,16 @@ var SignatureVerificationTime = prometheus.NewHistogram(prometheus.HistogramOpts
 	Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1},
 })
 
+var SignatureCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_signature_cache_hits_total",
+	Help: "Total number of signatures found in the verified-signature cache",
+})
+
+var SignatureCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "lean_signature_cache_misses_total",
+	Help: "Total number of signatures not found in the verified-signature cache",
+})
+
 var SigningTime = prometheus.NewHistogram(prometheus.HistogramOpts{
 	Name:    "lean_signing_time_seconds",
 	Help:    "Time to produce a single XMSS signature",
@@ -157,7 +274,14 @@ func init() {
 		ForkChoiceBlockProcessingTime,
 		AttestationsValid,
 		AttestationsInvalid,
+		AttestationsDeferred,
+		AttestationsResolved,
+		AttestationsExpired,
 		AttestationValidationTime,
+		ForkChoicePrunedBlocks,
+		ForkChoicePrunedStates,
+		ForkChoicePrunedAttestations,
+		SlashingsDetected,
 		// State transition
 		LatestJustifiedSlot,
 		LatestFinalizedSlot,
@@ -169,10 +293,27 @@ func init() {
 		STFAttestationsProcessingTime,
 		// Validator
 		ValidatorsCount,
+		XMSSKeyEpochsRemaining,
 		// Network
 		ConnectedPeers,
+		PeerDials,
+		PeerDisconnects,
+		PeerGoodbyes,
+		ReqRespRateLimited,
+		GossipValidations,
+		GossipPeerScore,
+		GossipPeersByScore,
+		// Sync
+		BackfillOldestSlot,
+		BackfillBlocks,
+		PendingBlocks,
+		OrphanParentRequests,
+		SyncState,
+		SyncBatches,
 		// Devnet-1 baselines
 		SignatureVerificationTime,
+		SignatureCacheHits,
+		SignatureCacheMisses,
 		SigningTime,
 		AggregateSizeBytes,
 	)
@@ -0,0 +1,196 @@
+package slashprotection
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	goleveldb "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// InterchangeVersion is the interchange format version written by Export.
+const InterchangeVersion = "5"
+
+// Interchange is the slashing-protection interchange format, modelled on
+// EIP-3076 with slots in place of epochs. Integers are decimal strings and
+// byte strings 0x-prefixed hex, as in EIP-3076.
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []InterchangeRecord `json:"data"`
+}
+
+// InterchangeMetadata identifies the format and the chain.
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string `json:"interchange_format_version"`
+	GenesisValidatorsRoot    string `json:"genesis_validators_root"`
+}
+
+// InterchangeRecord is the signing history of one validator.
+type InterchangeRecord struct {
+	Pubkey             string                   `json:"pubkey"`
+	SignedBlocks       []InterchangeBlock       `json:"signed_blocks"`
+	SignedAttestations []InterchangeAttestation `json:"signed_attestations"`
+}
+
+// InterchangeBlock is a signed block slot.
+type InterchangeBlock struct {
+	Slot string `json:"slot"`
+}
+
+// InterchangeAttestation is a signed source/target vote. Slot is the
+// attestation slot; it is only known for the most recent attestation.
+type InterchangeAttestation struct {
+	Slot       string `json:"slot,omitempty"`
+	SourceSlot string `json:"source_slot"`
+	TargetSlot string `json:"target_slot"`
+	TargetRoot string `json:"target_root"`
+}
+
+// Export returns the signing history of every validator in the registry
+// that has one.
+func (d *DB) Export(validators []*types.Validator) (*Interchange, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	gvr, err := types.ValidatorsRoot(validators)
+	if err != nil {
+		return nil, fmt.Errorf("validators root: %w", err)
+	}
+	ic := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeVersion,
+			GenesisValidatorsRoot:    "0x" + hex.EncodeToString(gvr[:]),
+		},
+		Data: []InterchangeRecord{},
+	}
+
+	for _, v := range validators {
+		rec := InterchangeRecord{
+			Pubkey:             "0x" + hex.EncodeToString(v.Pubkey[:]),
+			SignedBlocks:       []InterchangeBlock{},
+			SignedAttestations: []InterchangeAttestation{},
+		}
+
+		if slot, ok, err := d.getUint(validatorKey(blockPrefix, v.Index)); err != nil {
+			return nil, err
+		} else if ok {
+			rec.SignedBlocks = append(rec.SignedBlocks, InterchangeBlock{Slot: strconv.FormatUint(slot, 10)})
+		}
+
+		iter := d.db.NewIterator(util.BytesPrefix(validatorKey(attestationPrefix, v.Index)), nil)
+		for iter.Next() {
+			rec.SignedAttestations = append(rec.SignedAttestations, InterchangeAttestation{
+				SourceSlot: strconv.FormatUint(binary.BigEndian.Uint64(iter.Value()[:8]), 10),
+				TargetSlot: strconv.FormatUint(binary.BigEndian.Uint64(iter.Key()[9:]), 10),
+				TargetRoot: "0x" + hex.EncodeToString(iter.Value()[8:40]),
+			})
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+
+		if slot, ok, err := d.getUint(validatorKey(attSlotPrefix, v.Index)); err != nil {
+			return nil, err
+		} else if ok && len(rec.SignedAttestations) > 0 {
+			rec.SignedAttestations[len(rec.SignedAttestations)-1].Slot = strconv.FormatUint(slot, 10)
+		}
+
+		if len(rec.SignedBlocks) > 0 || len(rec.SignedAttestations) > 0 {
+			ic.Data = append(ic.Data, rec)
+		}
+	}
+	return ic, nil
+}
+
+// Import merges an interchange into the database. Existing records are
+// never relaxed: slots only move forward and votes already recorded are kept.
+// Pubkeys are mapped to validator indices through the registry.
+func (d *DB) Import(ic *Interchange, validators []*types.Validator) error {
+	gvr, err := types.ValidatorsRoot(validators)
+	if err != nil {
+		return fmt.Errorf("validators root: %w", err)
+	}
+	want := "0x" + hex.EncodeToString(gvr[:])
+	if !strings.EqualFold(ic.Metadata.GenesisValidatorsRoot, want) {
+		return fmt.Errorf("interchange genesis validators root %s does not match %s", ic.Metadata.GenesisValidatorsRoot, want)
+	}
+
+	indices := make(map[string]uint64, len(validators))
+	for _, v := range validators {
+		indices["0x"+hex.EncodeToString(v.Pubkey[:])] = v.Index
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	batch := new(goleveldb.Batch)
+	blockSlots := make(map[uint64]uint64)
+	attSlots := make(map[uint64]uint64)
+	for _, rec := range ic.Data {
+		validator, ok := indices[strings.ToLower(rec.Pubkey)]
+		if !ok {
+			return fmt.Errorf("unknown validator pubkey %s", rec.Pubkey)
+		}
+
+		for _, b := range rec.SignedBlocks {
+			slot, err := strconv.ParseUint(b.Slot, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid block slot %q: %w", b.Slot, err)
+			}
+			blockSlots[validator] = max(blockSlots[validator], slot)
+		}
+
+		for _, a := range rec.SignedAttestations {
+			source, err1 := strconv.ParseUint(a.SourceSlot, 10, 64)
+			target, err2 := strconv.ParseUint(a.TargetSlot, 10, 64)
+			root, err3 := hex.DecodeString(strings.TrimPrefix(a.TargetRoot, "0x"))
+			if err1 != nil || err2 != nil || err3 != nil || len(root) != 32 {
+				return fmt.Errorf("invalid attestation record %+v", a)
+			}
+			// An attestation's slot is never below its target slot.
+			slot := target
+			if a.Slot != "" {
+				if slot, err = strconv.ParseUint(a.Slot, 10, 64); err != nil {
+					return fmt.Errorf("invalid attestation slot %q: %w", a.Slot, err)
+				}
+			}
+			attSlots[validator] = max(attSlots[validator], slot)
+			if _, _, ok, err := d.getVote(validator, target); err != nil {
+				return err
+			} else if !ok {
+				value := binary.BigEndian.AppendUint64(nil, source)
+				batch.Put(voteKey(validator, target), append(value, root...))
+			}
+		}
+	}
+
+	for validator, slot := range blockSlots {
+		if err := d.raiseUint(batch, validatorKey(blockPrefix, validator), slot); err != nil {
+			return err
+		}
+	}
+	for validator, slot := range attSlots {
+		if err := d.raiseUint(batch, validatorKey(attSlotPrefix, validator), slot); err != nil {
+			return err
+		}
+	}
+	return d.db.Write(batch, syncWrite)
+}
+
+// raiseUint sets key to v in batch unless the stored value is already higher.
+func (d *DB) raiseUint(batch *goleveldb.Batch, key []byte, v uint64) error {
+	current, ok, err := d.getUint(key)
+	if err != nil {
+		return err
+	}
+	if !ok || v > current {
+		batch.Put(key, uint64Bytes(v))
+	}
+	return nil
+}
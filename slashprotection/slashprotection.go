@@ -0,0 +1,190 @@
+// Package slashprotection records what each local validator has signed and
+// refuses to sign anything that conflicts with it.
+//
+// Blocks: a validator never signs a block at or below the slot of a block
+// it signed before.
+//
+// Attestations: a validator never signs
+//   - an attestation at or below the slot of one it signed before (XMSS keys
+//     are one-time per slot, and this also rules out double signing a slot),
+//   - a double vote: a different target root for a target slot it voted on,
+//   - a surround vote: a source/target span that surrounds, or is surrounded
+//     by, one it signed before.
+package slashprotection
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	goleveldb "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// ErrSlashable is returned when signing would be slashable or reuse a slot.
+var ErrSlashable = errors.New("refusing to sign")
+
+// Key layout, all integers big-endian so keys sort by slot:
+//
+//	b | validator             -> last signed block slot
+//	s | validator             -> last signed attestation slot
+//	a | validator | target    -> source slot | target root
+var (
+	blockPrefix       = []byte("b")
+	attSlotPrefix     = []byte("s")
+	attestationPrefix = []byte("a")
+)
+
+// syncWrite flushes to disk before a signature is released.
+var syncWrite = &opt.WriteOptions{Sync: true}
+
+// DB is a persistent slashing-protection database.
+type DB struct {
+	mu sync.Mutex // serializes check-and-record
+	db *goleveldb.DB
+}
+
+// Open opens (or creates) a slashing-protection database at path.
+func Open(path string) (*DB, error) {
+	db, err := goleveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open slashing protection db at %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// CheckBlock records that validator signs a block at slot, or returns an
+// error wrapping ErrSlashable if it must not.
+func (d *DB) CheckBlock(validator, slot uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok, err := d.getUint(validatorKey(blockPrefix, validator)); err != nil {
+		return err
+	} else if ok && slot <= last {
+		return fmt.Errorf("%w: block at slot %d, already signed block at slot %d", ErrSlashable, slot, last)
+	}
+	return d.db.Put(validatorKey(blockPrefix, validator), uint64Bytes(slot), syncWrite)
+}
+
+// CheckAttestation records that validator signs an attestation with the
+// given data, or returns an error wrapping ErrSlashable if it must not.
+func (d *DB) CheckAttestation(validator uint64, data *types.AttestationData) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.checkAttestation(validator, data.Slot, data.Source.Slot, data.Target.Slot, data.Target.Root); err != nil {
+		return err
+	}
+	batch := new(goleveldb.Batch)
+	d.recordAttestation(batch, validator, data.Slot, data.Source.Slot, data.Target.Slot, data.Target.Root)
+	return d.db.Write(batch, syncWrite)
+}
+
+func (d *DB) checkAttestation(validator, slot, source, target uint64, targetRoot [32]byte) error {
+	if last, ok, err := d.getUint(validatorKey(attSlotPrefix, validator)); err != nil {
+		return err
+	} else if ok && slot <= last {
+		return fmt.Errorf("%w: attestation at slot %d, already signed attestation at slot %d", ErrSlashable, slot, last)
+	}
+
+	prevSource, prevRoot, ok, err := d.getVote(validator, target)
+	if err != nil {
+		return err
+	}
+	if ok && prevRoot != targetRoot {
+		return fmt.Errorf("%w: double vote for target slot %d", ErrSlashable, target)
+	}
+	if ok && prevSource != source {
+		return fmt.Errorf("%w: target slot %d already voted with source slot %d", ErrSlashable, target, prevSource)
+	}
+
+	// Surrounded by an earlier vote: it has a later target and an earlier source.
+	if prevSource, prevTarget, ok, err := d.findVote(voteRange(validator, target+1, ^uint64(0)), func(s uint64) bool { return s < source }); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("%w: vote %d->%d surrounded by %d->%d", ErrSlashable, source, target, prevSource, prevTarget)
+	}
+
+	// Surrounds an earlier vote: it has an earlier target and a later source.
+	if target > source+1 {
+		if prevSource, prevTarget, ok, err := d.findVote(voteRange(validator, source+1, target), func(s uint64) bool { return s > source }); err != nil {
+			return err
+		} else if ok {
+			return fmt.Errorf("%w: vote %d->%d surrounds %d->%d", ErrSlashable, source, target, prevSource, prevTarget)
+		}
+	}
+	return nil
+}
+
+// findVote returns the first recorded vote in r whose source slot matches.
+func (d *DB) findVote(r *util.Range, match func(source uint64) bool) (source, target uint64, ok bool, err error) {
+	iter := d.db.NewIterator(r, nil)
+	defer iter.Release()
+	for iter.Next() {
+		if source := binary.BigEndian.Uint64(iter.Value()[:8]); match(source) {
+			return source, binary.BigEndian.Uint64(iter.Key()[9:]), true, nil
+		}
+	}
+	return 0, 0, false, iter.Error()
+}
+
+func (d *DB) recordAttestation(batch *goleveldb.Batch, validator, slot, source, target uint64, targetRoot [32]byte) {
+	batch.Put(validatorKey(attSlotPrefix, validator), uint64Bytes(slot))
+	value := make([]byte, 0, 40)
+	value = binary.BigEndian.AppendUint64(value, source)
+	value = append(value, targetRoot[:]...)
+	batch.Put(voteKey(validator, target), value)
+}
+
+func (d *DB) getVote(validator, target uint64) (uint64, [32]byte, bool, error) {
+	value, err := d.db.Get(voteKey(validator, target), nil)
+	if errors.Is(err, goleveldb.ErrNotFound) {
+		return 0, [32]byte{}, false, nil
+	}
+	if err != nil {
+		return 0, [32]byte{}, false, err
+	}
+	return binary.BigEndian.Uint64(value[:8]), [32]byte(value[8:40]), true, nil
+}
+
+func (d *DB) getUint(key []byte) (uint64, bool, error) {
+	value, err := d.db.Get(key, nil)
+	if errors.Is(err, goleveldb.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+func validatorKey(prefix []byte, validator uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, prefix...), validator)
+}
+
+func voteKey(validator, target uint64) []byte {
+	return binary.BigEndian.AppendUint64(validatorKey(attestationPrefix, validator), target)
+}
+
+// voteRange covers a validator's votes with target slots in [from, to).
+func voteRange(validator, from, to uint64) *util.Range {
+	limit := voteKey(validator, to)
+	if to == ^uint64(0) {
+		limit = validatorKey(attestationPrefix, validator+1)
+	}
+	return &util.Range{Start: voteKey(validator, from), Limit: limit}
+}
+
+func uint64Bytes(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
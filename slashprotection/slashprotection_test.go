@@ -0,0 +1,141 @@
+package slashprotection_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/geanlabs/gean/slashprotection"
+	"github.com/geanlabs/gean/types"
+)
+
+func openTestDB(t *testing.T, dir string) *slashprotection.DB {
+	t.Helper()
+	db, err := slashprotection.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return db
+}
+
+func vote(slot, source, target uint64, root byte) *types.AttestationData {
+	return &types.AttestationData{
+		Slot:   slot,
+		Head:   &types.Checkpoint{Root: [32]byte{root}, Slot: slot},
+		Source: &types.Checkpoint{Slot: source},
+		Target: &types.Checkpoint{Root: [32]byte{root}, Slot: target},
+	}
+}
+
+func TestCheckBlockRefusesRepeatedSlot(t *testing.T) {
+	db := openTestDB(t, t.TempDir())
+	defer db.Close()
+
+	if err := db.CheckBlock(1, 5); err != nil {
+		t.Fatalf("first block: %v", err)
+	}
+	for _, slot := range []uint64{5, 4} {
+		if err := db.CheckBlock(1, slot); !errors.Is(err, slashprotection.ErrSlashable) {
+			t.Fatalf("block at slot %d: err = %v, want ErrSlashable", slot, err)
+		}
+	}
+	if err := db.CheckBlock(2, 5); err != nil {
+		t.Fatalf("other validator: %v", err)
+	}
+	if err := db.CheckBlock(1, 6); err != nil {
+		t.Fatalf("later block: %v", err)
+	}
+}
+
+func TestCheckAttestation(t *testing.T) {
+	db := openTestDB(t, t.TempDir())
+	defer db.Close()
+
+	// Consecutive slots commonly share a target.
+	for _, d := range []*types.AttestationData{vote(4, 0, 3, 0xa), vote(5, 0, 3, 0xa), vote(9, 3, 6, 0xb)} {
+		if err := db.CheckAttestation(1, d); err != nil {
+			t.Fatalf("attestation at slot %d: %v", d.Slot, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		data *types.AttestationData
+	}{
+		{"same slot", vote(9, 3, 7, 0xc)},
+		{"double vote", vote(10, 3, 6, 0xc)},
+		{"surrounding", vote(11, 1, 8, 0xc)},
+		{"surrounded", vote(12, 4, 5, 0xc)},
+	}
+	for _, tt := range tests {
+		if err := db.CheckAttestation(1, tt.data); !errors.Is(err, slashprotection.ErrSlashable) {
+			t.Fatalf("%s: err = %v, want ErrSlashable", tt.name, err)
+		}
+	}
+
+	// A refused attestation is not recorded, so a valid one can follow.
+	if err := db.CheckAttestation(1, vote(10, 6, 9, 0xd)); err != nil {
+		t.Fatalf("valid attestation after refusals: %v", err)
+	}
+}
+
+func TestRecordsSurviveReopen(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	if err := db.CheckBlock(1, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CheckAttestation(1, vote(5, 0, 3, 0xa)); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db = openTestDB(t, dir)
+	defer db.Close()
+	if err := db.CheckBlock(1, 5); !errors.Is(err, slashprotection.ErrSlashable) {
+		t.Fatalf("block after reopen: err = %v, want ErrSlashable", err)
+	}
+	if err := db.CheckAttestation(1, vote(6, 0, 3, 0xb)); !errors.Is(err, slashprotection.ErrSlashable) {
+		t.Fatalf("double vote after reopen: err = %v, want ErrSlashable", err)
+	}
+}
+
+func TestInterchangeRoundTrip(t *testing.T) {
+	validators := []*types.Validator{{Pubkey: [52]byte{1}, Index: 0}, {Pubkey: [52]byte{2}, Index: 1}}
+
+	src := openTestDB(t, t.TempDir())
+	defer src.Close()
+	if err := src.CheckBlock(1, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CheckAttestation(1, vote(7, 0, 3, 0xa)); err != nil {
+		t.Fatal(err)
+	}
+
+	ic, err := src.Export(validators)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(ic.Data) != 1 {
+		t.Fatalf("exported %d validators, want 1", len(ic.Data))
+	}
+
+	dst := openTestDB(t, t.TempDir())
+	defer dst.Close()
+	if err := dst.Import(ic, validators); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if err := dst.CheckBlock(1, 5); !errors.Is(err, slashprotection.ErrSlashable) {
+		t.Fatalf("imported block: err = %v, want ErrSlashable", err)
+	}
+	if err := dst.CheckAttestation(1, vote(7, 0, 4, 0xb)); !errors.Is(err, slashprotection.ErrSlashable) {
+		t.Fatalf("imported attestation slot: err = %v, want ErrSlashable", err)
+	}
+	if err := dst.CheckAttestation(1, vote(8, 0, 3, 0xb)); !errors.Is(err, slashprotection.ErrSlashable) {
+		t.Fatalf("imported vote: err = %v, want ErrSlashable", err)
+	}
+
+	other := []*types.Validator{{Pubkey: [52]byte{9}, Index: 0}}
+	if err := dst.Import(ic, other); err == nil {
+		t.Fatal("expected import for a different chain to fail")
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/binary"
+
+	"github.com/geanlabs/gean/types"
+)
+
+// EvidenceKind is the offence an Evidence proves.
+type EvidenceKind byte
+
+const (
+	// ProposerEquivocation is two distinct blocks by one proposer for one slot.
+	ProposerEquivocation EvidenceKind = iota + 1
+	// DoubleVote is two attestations by one validator for the same slot
+	// with different data, or for the same target slot with a different
+	// target or source.
+	DoubleVote
+	// SurroundVote is two attestations by one validator whose source to
+	// target spans surround one another.
+	SurroundVote
+)
+
+func (k EvidenceKind) String() string {
+	switch k {
+	case ProposerEquivocation:
+		return "proposer_equivocation"
+	case DoubleVote:
+		return "double_vote"
+	case SurroundVote:
+		return "surround_vote"
+	default:
+		return "unknown"
+	}
+}
+
+// Evidence is a pair of conflicting messages signed by one validator.
+// Blocks is set for proposer equivocations, Attestations for double and
+// surround votes; the first message is the one seen first.
+type Evidence struct {
+	Kind      EvidenceKind
+	Validator uint64
+	// Slot is the slot of the second message.
+	Slot         uint64
+	Blocks       [2]*types.SignedBlockWithAttestation
+	Attestations [2]*types.SignedAttestation
+}
+
+// ID identifies the evidence: its slot, validator and kind, followed by the
+// roots of both messages. IDs sort by slot.
+func (e *Evidence) ID() []byte {
+	id := make([]byte, 0, 17+2*32)
+	id = binary.BigEndian.AppendUint64(id, e.Slot)
+	id = binary.BigEndian.AppendUint64(id, e.Validator)
+	id = append(id, byte(e.Kind))
+	for i := range 2 {
+		var root [32]byte
+		switch {
+		case e.Blocks[i] != nil:
+			root, _ = e.Blocks[i].Message.Block.HashTreeRoot()
+		case e.Attestations[i] != nil:
+			root, _ = e.Attestations[i].Message.Data.HashTreeRoot()
+		}
+		id = append(id, root[:]...)
+	}
+	return id
+}
@@ -10,6 +10,31 @@ type Store interface {
 	PutSignedBlock(root [32]byte, sb *types.SignedBlockWithAttestation)
 	GetState(root [32]byte) (*types.State, bool)
 	PutState(root [32]byte, state *types.State)
+	// PutBlockWithState writes a block, its signed envelope and its
+	// post-state in one atomic write, so a block is never stored without
+	// the state needed to build on it.
+	PutBlockWithState(root [32]byte, sb *types.SignedBlockWithAttestation, state *types.State)
 	GetAllBlocks() map[[32]byte]*types.Block
 	GetAllStates() map[[32]byte]*types.State
+	GetCheckpoints() (*Checkpoints, bool)
+	PutCheckpoints(cp *Checkpoints)
+
+	// DeleteBlock removes a block and its signed envelope.
+	DeleteBlock(root [32]byte)
+	// DeleteState removes the post-state of a block.
+	DeleteState(root [32]byte)
+
+	// PutEvidence records proof that a validator equivocated. Evidence
+	// whose ID is already recorded is stored once.
+	PutEvidence(ev *Evidence)
+	// GetAllEvidence returns all recorded evidence, ordered by ID.
+	GetAllEvidence() []*Evidence
+}
+
+// Checkpoints is the fork choice position persisted so a node can resume
+// from where it stopped instead of regenerating genesis.
+type Checkpoints struct {
+	Head      *types.Checkpoint
+	Justified *types.Checkpoint
+	Finalized *types.Checkpoint
 }
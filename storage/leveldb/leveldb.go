@@ -0,0 +1,341 @@
+package leveldb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	goleveldb "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/types"
+)
+
+var log = logging.NewComponentLogger(logging.CompStorage)
+
+// Key prefixes. Every value is stored SSZ-encoded under prefix + root,
+// except evidence, which is stored under prefix + Evidence.ID (see
+// encodeEvidence).
+var (
+	blockPrefix       = []byte("b")
+	signedBlockPrefix = []byte("s")
+	statePrefix       = []byte("t")
+	evidencePrefix    = []byte("e")
+	checkpointsKey    = []byte("checkpoints")
+)
+
+// checkpointSize is the SSZ size of a single types.Checkpoint.
+const checkpointSize = 40
+
+// syncWrite flushes the write-ahead log before returning so that the
+// persisted fork choice position survives an OS crash, not just a process one.
+var syncWrite = &opt.WriteOptions{Sync: true}
+
+// Store is an on-disk implementation of storage.Store backed by LevelDB.
+//
+// Each Put is a single LevelDB write and therefore atomic on its own.
+// PutBlockWithState writes a block and its state in one synced batch, so a
+// block that is present after a crash is always importable.
+//
+// Nothing is cached in memory; fork choice keeps its own block tree and
+// only reads every block back on restart.
+type Store struct {
+	db *goleveldb.DB
+}
+
+// Open opens (or creates) a LevelDB store at path.
+func Open(path string) (*Store, error) {
+	db, err := goleveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open leveldb at %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close flushes and closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) GetBlock(root [32]byte) (*types.Block, bool) {
+	data, ok := s.get(key(blockPrefix, root))
+	if !ok {
+		return nil, false
+	}
+	block := new(types.Block)
+	if err := block.UnmarshalSSZ(data); err != nil {
+		log.Error("failed to decode block", "block_root", logging.ShortHash(root), "err", err)
+		return nil, false
+	}
+	return block, true
+}
+
+func (s *Store) PutBlock(root [32]byte, block *types.Block) {
+	data, err := block.MarshalSSZ()
+	if err != nil {
+		log.Error("failed to encode block", "block_root", logging.ShortHash(root), "err", err)
+		return
+	}
+	s.put(key(blockPrefix, root), data)
+}
+
+func (s *Store) GetSignedBlock(root [32]byte) (*types.SignedBlockWithAttestation, bool) {
+	data, ok := s.get(key(signedBlockPrefix, root))
+	if !ok {
+		return nil, false
+	}
+	sb := new(types.SignedBlockWithAttestation)
+	if err := sb.UnmarshalSSZ(data); err != nil {
+		log.Error("failed to decode signed block", "block_root", logging.ShortHash(root), "err", err)
+		return nil, false
+	}
+	return sb, true
+}
+
+func (s *Store) PutSignedBlock(root [32]byte, sb *types.SignedBlockWithAttestation) {
+	data, err := sb.MarshalSSZ()
+	if err != nil {
+		log.Error("failed to encode signed block", "block_root", logging.ShortHash(root), "err", err)
+		return
+	}
+	s.put(key(signedBlockPrefix, root), data)
+}
+
+func (s *Store) GetState(root [32]byte) (*types.State, bool) {
+	data, ok := s.get(key(statePrefix, root))
+	if !ok {
+		return nil, false
+	}
+	state := new(types.State)
+	if err := state.UnmarshalSSZ(data); err != nil {
+		log.Error("failed to decode state", "block_root", logging.ShortHash(root), "err", err)
+		return nil, false
+	}
+	return state, true
+}
+
+func (s *Store) PutState(root [32]byte, state *types.State) {
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		log.Error("failed to encode state", "block_root", logging.ShortHash(root), "err", err)
+		return
+	}
+	s.put(key(statePrefix, root), data)
+}
+
+func (s *Store) PutBlockWithState(root [32]byte, sb *types.SignedBlockWithAttestation, state *types.State) {
+	batch := new(goleveldb.Batch)
+	for _, v := range []struct {
+		prefix []byte
+		value  interface{ MarshalSSZ() ([]byte, error) }
+	}{
+		{statePrefix, state},
+		{signedBlockPrefix, sb},
+		{blockPrefix, sb.Message.Block},
+	} {
+		data, err := v.value.MarshalSSZ()
+		if err != nil {
+			log.Error("failed to encode block with state", "block_root", logging.ShortHash(root), "err", err)
+			return
+		}
+		batch.Put(key(v.prefix, root), data)
+	}
+	if err := s.db.Write(batch, syncWrite); err != nil {
+		log.Error("failed to write block with state", "block_root", logging.ShortHash(root), "err", err)
+	}
+}
+
+func (s *Store) GetAllBlocks() map[[32]byte]*types.Block {
+	blocks := make(map[[32]byte]*types.Block)
+	iter := s.db.NewIterator(util.BytesPrefix(blockPrefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var root [32]byte
+		copy(root[:], iter.Key()[len(blockPrefix):])
+		block := new(types.Block)
+		if err := block.UnmarshalSSZ(iter.Value()); err != nil {
+			log.Error("failed to decode block", "block_root", logging.ShortHash(root), "err", err)
+			continue
+		}
+		blocks[root] = block
+	}
+	return blocks
+}
+
+func (s *Store) GetAllStates() map[[32]byte]*types.State {
+	states := make(map[[32]byte]*types.State)
+	iter := s.db.NewIterator(util.BytesPrefix(statePrefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var root [32]byte
+		copy(root[:], iter.Key()[len(statePrefix):])
+		state := new(types.State)
+		if err := state.UnmarshalSSZ(iter.Value()); err != nil {
+			log.Error("failed to decode state", "block_root", logging.ShortHash(root), "err", err)
+			continue
+		}
+		states[root] = state
+	}
+	return states
+}
+
+func (s *Store) GetCheckpoints() (*storage.Checkpoints, bool) {
+	data, ok := s.get(checkpointsKey)
+	if !ok {
+		return nil, false
+	}
+	if len(data) != 3*checkpointSize {
+		log.Error("invalid checkpoints record", "len", len(data))
+		return nil, false
+	}
+	cps := make([]*types.Checkpoint, 3)
+	for i := range cps {
+		cps[i] = new(types.Checkpoint)
+		if err := cps[i].UnmarshalSSZ(data[i*checkpointSize : (i+1)*checkpointSize]); err != nil {
+			log.Error("failed to decode checkpoints", "err", err)
+			return nil, false
+		}
+	}
+	return &storage.Checkpoints{Head: cps[0], Justified: cps[1], Finalized: cps[2]}, true
+}
+
+func (s *Store) PutCheckpoints(cp *storage.Checkpoints) {
+	data := make([]byte, 0, 3*checkpointSize)
+	for _, c := range []*types.Checkpoint{cp.Head, cp.Justified, cp.Finalized} {
+		var err error
+		if data, err = c.MarshalSSZTo(data); err != nil {
+			log.Error("failed to encode checkpoints", "err", err)
+			return
+		}
+	}
+
+	if err := s.db.Put(checkpointsKey, data, syncWrite); err != nil {
+		log.Error("failed to write checkpoints", "err", err)
+	}
+}
+
+func (s *Store) DeleteBlock(root [32]byte) {
+	batch := new(goleveldb.Batch)
+	batch.Delete(key(blockPrefix, root))
+	batch.Delete(key(signedBlockPrefix, root))
+	if err := s.db.Write(batch, nil); err != nil {
+		log.Error("failed to delete block", "block_root", logging.ShortHash(root), "err", err)
+	}
+}
+
+func (s *Store) DeleteState(root [32]byte) {
+	if err := s.db.Delete(key(statePrefix, root), nil); err != nil {
+		log.Error("failed to delete state", "block_root", logging.ShortHash(root), "err", err)
+	}
+}
+
+func (s *Store) PutEvidence(ev *storage.Evidence) {
+	data, err := encodeEvidence(ev)
+	if err != nil {
+		log.Error("failed to encode evidence", "kind", ev.Kind.String(), "validator", ev.Validator, "err", err)
+		return
+	}
+	if err := s.db.Put(append(slices.Clone(evidencePrefix), ev.ID()...), data, syncWrite); err != nil {
+		log.Error("failed to write evidence", "kind", ev.Kind.String(), "validator", ev.Validator, "err", err)
+	}
+}
+
+func (s *Store) GetAllEvidence() []*storage.Evidence {
+	var out []*storage.Evidence
+	iter := s.db.NewIterator(util.BytesPrefix(evidencePrefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		ev, err := decodeEvidence(iter.Value())
+		if err != nil {
+			log.Error("failed to decode evidence", "key", fmt.Sprintf("%x", iter.Key()), "err", err)
+			continue
+		}
+		out = append(out, ev)
+	}
+	if err := iter.Error(); err != nil {
+		log.Error("failed to read evidence", "err", err)
+	}
+	return out
+}
+
+// encodeEvidence encodes evidence as kind (1 byte), validator and slot
+// (8 bytes each, little-endian), then each message as a 4-byte
+// little-endian length and its SSZ encoding.
+func encodeEvidence(ev *storage.Evidence) ([]byte, error) {
+	data := []byte{byte(ev.Kind)}
+	data = binary.LittleEndian.AppendUint64(data, ev.Validator)
+	data = binary.LittleEndian.AppendUint64(data, ev.Slot)
+	for i := range 2 {
+		var msg []byte
+		var err error
+		if ev.Kind == storage.ProposerEquivocation {
+			msg, err = ev.Blocks[i].MarshalSSZ()
+		} else {
+			msg, err = ev.Attestations[i].MarshalSSZ()
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(msg)))
+		data = append(data, msg...)
+	}
+	return data, nil
+}
+
+func decodeEvidence(data []byte) (*storage.Evidence, error) {
+	if len(data) < 17 {
+		return nil, fmt.Errorf("evidence record too short: %d bytes", len(data))
+	}
+	ev := &storage.Evidence{
+		Kind:      storage.EvidenceKind(data[0]),
+		Validator: binary.LittleEndian.Uint64(data[1:9]),
+		Slot:      binary.LittleEndian.Uint64(data[9:17]),
+	}
+	data = data[17:]
+	for i := range 2 {
+		if len(data) < 4 || uint64(len(data)-4) < uint64(binary.LittleEndian.Uint32(data)) {
+			return nil, fmt.Errorf("evidence message %d truncated", i)
+		}
+		n := binary.LittleEndian.Uint32(data)
+		msg := data[4 : 4+n]
+		data = data[4+n:]
+		if ev.Kind == storage.ProposerEquivocation {
+			ev.Blocks[i] = new(types.SignedBlockWithAttestation)
+			if err := ev.Blocks[i].UnmarshalSSZ(msg); err != nil {
+				return nil, err
+			}
+		} else {
+			ev.Attestations[i] = new(types.SignedAttestation)
+			if err := ev.Attestations[i].UnmarshalSSZ(msg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ev, nil
+}
+
+func (s *Store) get(k []byte) ([]byte, bool) {
+	data, err := s.db.Get(k, nil)
+	if err != nil {
+		if err != goleveldb.ErrNotFound {
+			log.Error("failed to read key", "key", fmt.Sprintf("%x", k), "err", err)
+		}
+		return nil, false
+	}
+	return data, true
+}
+
+func (s *Store) put(k, data []byte) {
+	if err := s.db.Put(k, data, nil); err != nil {
+		log.Error("failed to write key", "key", fmt.Sprintf("%x", k), "err", err)
+	}
+}
+
+func key(prefix []byte, root [32]byte) []byte {
+	k := make([]byte, 0, len(prefix)+32)
+	k = append(k, prefix...)
+	return append(k, root[:]...)
+}
@@ -0,0 +1,247 @@
+package leveldb_test
+
+import (
+	"testing"
+
+	"github.com/geanlabs/gean/storage"
+	"github.com/geanlabs/gean/storage/leveldb"
+	"github.com/geanlabs/gean/types"
+)
+
+func openTestStore(t *testing.T, dir string) *leveldb.Store {
+	t.Helper()
+	s, err := leveldb.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+func testBlock(slot uint64) *types.Block {
+	return &types.Block{
+		Slot:       slot,
+		ParentRoot: [32]byte{byte(slot)},
+		Body:       &types.BlockBody{Attestations: []*types.Attestation{}},
+	}
+}
+
+func TestPutGetBlock(t *testing.T) {
+	s := openTestStore(t, t.TempDir())
+	defer s.Close()
+	root := [32]byte{1}
+
+	s.PutBlock(root, testBlock(5))
+
+	got, ok := s.GetBlock(root)
+	if !ok {
+		t.Fatal("expected block to be found")
+	}
+	if got.Slot != 5 {
+		t.Fatalf("block slot = %d, want 5", got.Slot)
+	}
+}
+
+func TestPutGetSignedBlock(t *testing.T) {
+	s := openTestStore(t, t.TempDir())
+	defer s.Close()
+	root := [32]byte{1}
+	sb := &types.SignedBlockWithAttestation{
+		Message:   &types.BlockWithAttestation{Block: testBlock(3)},
+		Signature: [][3112]byte{{0xaa}},
+	}
+
+	s.PutSignedBlock(root, sb)
+
+	got, ok := s.GetSignedBlock(root)
+	if !ok {
+		t.Fatal("expected signed block to be found")
+	}
+	if got.Message.Block.Slot != 3 {
+		t.Fatalf("block slot = %d, want 3", got.Message.Block.Slot)
+	}
+	if len(got.Signature) != 1 || got.Signature[0][0] != 0xaa {
+		t.Fatal("signature not preserved")
+	}
+}
+
+func TestGetMissingReturnsFalse(t *testing.T) {
+	s := openTestStore(t, t.TempDir())
+	defer s.Close()
+	if _, ok := s.GetBlock([32]byte{0xff}); ok {
+		t.Fatal("expected missing block to return false")
+	}
+	if _, ok := s.GetState([32]byte{0xff}); ok {
+		t.Fatal("expected missing state to return false")
+	}
+	if _, ok := s.GetCheckpoints(); ok {
+		t.Fatal("expected missing checkpoints to return false")
+	}
+}
+
+func TestDataSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	root := [32]byte{7}
+	state := &types.State{
+		Config:                   &types.Config{GenesisTime: 1000},
+		Slot:                     9,
+		LatestBlockHeader:        &types.BlockHeader{},
+		LatestJustified:          &types.Checkpoint{},
+		LatestFinalized:          &types.Checkpoint{},
+		JustifiedSlots:           []byte{0x01},
+		JustificationsValidators: []byte{0x01},
+	}
+	cps := &storage.Checkpoints{
+		Head:      &types.Checkpoint{Root: root, Slot: 9},
+		Justified: &types.Checkpoint{Root: [32]byte{6}, Slot: 6},
+		Finalized: &types.Checkpoint{Root: [32]byte{3}, Slot: 3},
+	}
+
+	s := openTestStore(t, dir)
+	s.PutState(root, state)
+	s.PutBlock(root, testBlock(9))
+	s.PutCheckpoints(cps)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+
+	if b, ok := s.GetBlock(root); !ok || b.Slot != 9 {
+		t.Fatal("block not restored after reopen")
+	}
+	if len(s.GetAllBlocks()) != 1 {
+		t.Fatalf("GetAllBlocks len = %d, want 1", len(s.GetAllBlocks()))
+	}
+	if st, ok := s.GetState(root); !ok || st.Slot != 9 || st.Config.GenesisTime != 1000 {
+		t.Fatal("state not restored after reopen")
+	}
+	got, ok := s.GetCheckpoints()
+	if !ok {
+		t.Fatal("checkpoints not restored after reopen")
+	}
+	if *got.Head != *cps.Head || *got.Justified != *cps.Justified || *got.Finalized != *cps.Finalized {
+		t.Fatalf("checkpoints mismatch: got %+v", got)
+	}
+}
+
+func TestDeleteBlockAndState(t *testing.T) {
+	dir := t.TempDir()
+	root := [32]byte{1}
+
+	s := openTestStore(t, dir)
+	s.PutBlock(root, testBlock(1))
+	s.PutSignedBlock(root, &types.SignedBlockWithAttestation{
+		Message: &types.BlockWithAttestation{Block: testBlock(1)},
+	})
+	s.PutState(root, &types.State{Slot: 1})
+	s.DeleteBlock(root)
+	s.DeleteState(root)
+	s.Close()
+
+	// Deletes must be durable across a reopen.
+	s = openTestStore(t, dir)
+	defer s.Close()
+	if _, ok := s.GetBlock(root); ok {
+		t.Fatal("expected block to be deleted")
+	}
+	if _, ok := s.GetSignedBlock(root); ok {
+		t.Fatal("expected signed block to be deleted with its block")
+	}
+	if _, ok := s.GetState(root); ok {
+		t.Fatal("expected state to be deleted")
+	}
+}
+
+func TestEvidenceSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	att := func(head byte) *types.SignedAttestation {
+		return &types.SignedAttestation{
+			Message: &types.Attestation{
+				ValidatorID: 4,
+				Data: &types.AttestationData{
+					Slot:   7,
+					Head:   &types.Checkpoint{Root: [32]byte{head}, Slot: 7},
+					Target: &types.Checkpoint{Slot: 7},
+					Source: &types.Checkpoint{},
+				},
+			},
+			Signature: [3112]byte{head},
+		}
+	}
+	vote := &storage.Evidence{
+		Kind:         storage.DoubleVote,
+		Validator:    4,
+		Slot:         7,
+		Attestations: [2]*types.SignedAttestation{att(1), att(2)},
+	}
+	block := func(parent byte) *types.SignedBlockWithAttestation {
+		return &types.SignedBlockWithAttestation{
+			Message: &types.BlockWithAttestation{
+				Block:               &types.Block{Slot: 3, ParentRoot: [32]byte{parent}, Body: &types.BlockBody{Attestations: []*types.Attestation{}}},
+				ProposerAttestation: att(parent).Message,
+			},
+			Signature: [][3112]byte{{parent}},
+		}
+	}
+	proposal := &storage.Evidence{
+		Kind:   storage.ProposerEquivocation,
+		Slot:   3,
+		Blocks: [2]*types.SignedBlockWithAttestation{block(1), block(2)},
+	}
+
+	s := openTestStore(t, dir)
+	s.PutEvidence(vote)
+	s.PutEvidence(proposal)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+	got := s.GetAllEvidence()
+	if len(got) != 2 {
+		t.Fatalf("evidence = %d, want 2", len(got))
+	}
+	if got[0].Kind != storage.ProposerEquivocation || got[0].Blocks[1].Message.Block.ParentRoot != [32]byte{2} {
+		t.Fatalf("first evidence = %+v, want the slot 3 proposer equivocation", got[0])
+	}
+	if got[1].Kind != storage.DoubleVote || got[1].Validator != 4 || got[1].Attestations[1].Signature[0] != 2 {
+		t.Fatalf("second evidence = %+v, want the slot 7 double vote", got[1])
+	}
+}
+
+func TestPutBlockWithStateSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	root := [32]byte{4}
+	sb := &types.SignedBlockWithAttestation{
+		Message: &types.BlockWithAttestation{Block: testBlock(4)},
+	}
+	state := &types.State{
+		Config:                   &types.Config{GenesisTime: 1000},
+		Slot:                     4,
+		LatestBlockHeader:        &types.BlockHeader{},
+		LatestJustified:          &types.Checkpoint{},
+		LatestFinalized:          &types.Checkpoint{},
+		JustifiedSlots:           []byte{0x01},
+		JustificationsValidators: []byte{0x01},
+	}
+
+	s := openTestStore(t, dir)
+	s.PutBlockWithState(root, sb, state)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+	if b, ok := s.GetBlock(root); !ok || b.Slot != 4 {
+		t.Fatal("block not restored after reopen")
+	}
+	if _, ok := s.GetSignedBlock(root); !ok {
+		t.Fatal("signed block not restored after reopen")
+	}
+	if st, ok := s.GetState(root); !ok || st.Slot != 4 {
+		t.Fatal("state not restored after reopen")
+	}
+}
@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"slices"
 	"sync"
 
+	"github.com/geanlabs/gean/storage"
 	"github.com/geanlabs/gean/types"
 )
 
@@ -12,6 +14,8 @@ type Store struct {
 	blocks       map[[32]byte]*types.Block
 	signedBlocks map[[32]byte]*types.SignedBlockWithAttestation
 	states       map[[32]byte]*types.State
+	checkpoints  *storage.Checkpoints
+	evidence     map[string]*storage.Evidence
 }
 
 // New creates a new in-memory store.
@@ -20,6 +24,7 @@ func New() *Store {
 		blocks:       make(map[[32]byte]*types.Block),
 		signedBlocks: make(map[[32]byte]*types.SignedBlockWithAttestation),
 		states:       make(map[[32]byte]*types.State),
+		evidence:     make(map[string]*storage.Evidence),
 	}
 }
 
@@ -62,6 +67,14 @@ func (m *Store) PutState(root [32]byte, state *types.State) {
 	m.states[root] = state
 }
 
+func (m *Store) PutBlockWithState(root [32]byte, sb *types.SignedBlockWithAttestation, state *types.State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocks[root] = sb.Message.Block
+	m.signedBlocks[root] = sb
+	m.states[root] = state
+}
+
 func (m *Store) GetAllBlocks() map[[32]byte]*types.Block {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -81,3 +94,49 @@ func (m *Store) GetAllStates() map[[32]byte]*types.State {
 	}
 	return cp
 }
+
+func (m *Store) GetCheckpoints() (*storage.Checkpoints, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.checkpoints, m.checkpoints != nil
+}
+
+func (m *Store) PutCheckpoints(cp *storage.Checkpoints) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints = cp
+}
+
+func (m *Store) DeleteBlock(root [32]byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blocks, root)
+	delete(m.signedBlocks, root)
+}
+
+func (m *Store) DeleteState(root [32]byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, root)
+}
+
+func (m *Store) PutEvidence(ev *storage.Evidence) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evidence[string(ev.ID())] = ev
+}
+
+func (m *Store) GetAllEvidence() []*storage.Evidence {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.evidence))
+	for id := range m.evidence {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	out := make([]*storage.Evidence, len(ids))
+	for i, id := range ids {
+		out[i] = m.evidence[id]
+	}
+	return out
+}
@@ -3,6 +3,7 @@ package memory_test
 import (
 	"testing"
 
+	"github.com/geanlabs/gean/storage"
 	"github.com/geanlabs/gean/storage/memory"
 	"github.com/geanlabs/gean/types"
 )
@@ -85,3 +86,46 @@ func TestGetAllStatesCopiesMap(t *testing.T) {
 		t.Fatal("deleting from GetAllStates result should not affect store")
 	}
 }
+
+func TestPutGetCheckpoints(t *testing.T) {
+	s := memory.New()
+	if _, ok := s.GetCheckpoints(); ok {
+		t.Fatal("expected no checkpoints in a fresh store")
+	}
+
+	cps := &storage.Checkpoints{
+		Head:      &types.Checkpoint{Root: [32]byte{3}, Slot: 3},
+		Justified: &types.Checkpoint{Root: [32]byte{2}, Slot: 2},
+		Finalized: &types.Checkpoint{Root: [32]byte{1}, Slot: 1},
+	}
+	s.PutCheckpoints(cps)
+
+	got, ok := s.GetCheckpoints()
+	if !ok {
+		t.Fatal("expected checkpoints to be found")
+	}
+	if got.Head.Slot != 3 || got.Justified.Slot != 2 || got.Finalized.Slot != 1 {
+		t.Fatalf("checkpoints mismatch: got %+v", got)
+	}
+}
+
+func TestDeleteBlockAndState(t *testing.T) {
+	s := memory.New()
+	root := [32]byte{1}
+	s.PutBlock(root, &types.Block{Slot: 1})
+	s.PutSignedBlock(root, &types.SignedBlockWithAttestation{})
+	s.PutState(root, &types.State{Slot: 1})
+
+	s.DeleteBlock(root)
+	s.DeleteState(root)
+
+	if _, ok := s.GetBlock(root); ok {
+		t.Fatal("expected block to be deleted")
+	}
+	if _, ok := s.GetSignedBlock(root); ok {
+		t.Fatal("expected signed block to be deleted with its block")
+	}
+	if _, ok := s.GetState(root); ok {
+		t.Fatal("expected state to be deleted")
+	}
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// SpecVersion names the protocol version the node speaks. It is mixed into
+// the fork digest so nodes on incompatible versions keep apart.
+const SpecVersion = "pq-devnet-1"
+
+// ForkDigest identifies a network: the first four bytes of
+// sha256(validatorsRoot || uint64_le(genesisTime) || SpecVersion). Nodes with
+// the same genesis and spec version share a digest. It names gossip topics
+// and is advertised in the ENR.
+func ForkDigest(validatorsRoot [32]byte, genesisTime uint64) [4]byte {
+	var t [8]byte
+	binary.LittleEndian.PutUint64(t[:], genesisTime)
+	h := sha256.New()
+	h.Write(validatorsRoot[:])
+	h.Write(t[:])
+	h.Write([]byte(SpecVersion))
+	var digest [4]byte
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
@@ -0,0 +1,23 @@
+package types
+
+import ssz "github.com/ferranbt/fastssz"
+
+// ValidatorsRoot returns the hash tree root of a validator registry, the same
+// value State.Validators contributes to the state root. Since the registry is
+// fixed at genesis it identifies the chain, like a genesis validators root.
+func ValidatorsRoot(validators []*Validator) ([32]byte, error) {
+	if len(validators) > ValidatorRegistryLimit {
+		return [32]byte{}, ssz.ErrIncorrectListSize
+	}
+	hh := ssz.DefaultHasherPool.Get()
+	defer ssz.DefaultHasherPool.Put(hh)
+
+	indx := hh.Index()
+	for _, v := range validators {
+		if err := v.HashTreeRootWith(hh); err != nil {
+			return [32]byte{}, err
+		}
+	}
+	hh.MerkleizeWithMixin(indx, uint64(len(validators)), ValidatorRegistryLimit)
+	return hh.HashRoot()
+}
@@ -0,0 +1,220 @@
+// Package keymanager manages the state of XMSS validator keys.
+//
+// An XMSS key may sign at most one message per epoch, and only for epochs
+// inside its prepared window, which must be advanced as epochs go by. A
+// ManagedKey persists the last epoch it signed before releasing a
+// signature, refuses to sign at or below it again, and advances the
+// prepared window ahead of the wall clock, writing the updated secret key
+// back to disk.
+package keymanager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/geanlabs/gean/observability/logging"
+	"github.com/geanlabs/gean/observability/metrics"
+	"github.com/geanlabs/gean/xmss/leansig"
+)
+
+var log = logging.NewComponentLogger(logging.CompValidator)
+
+// ErrEpochUsed is returned when signing at or below the last signed epoch.
+var ErrEpochUsed = errors.New("xmss epoch already used")
+
+// expiryWarningFraction: a key warns once fewer than 1/expiryWarningFraction
+// of its active epochs remain.
+const expiryWarningFraction = 8
+
+// Key is the part of leansig.Keypair a ManagedKey uses.
+type Key interface {
+	Sign(epoch uint32, message [leansig.MessageLength]byte) ([]byte, error)
+	ActivationStart() uint64
+	ActivationEnd() uint64
+	PreparedStart() uint64
+	PreparedEnd() uint64
+	AdvancePreparation() error
+	SecretKeyBytes() ([]byte, error)
+}
+
+// ManagedKey wraps a validator's XMSS key. It implements forkchoice.Signer.
+type ManagedKey struct {
+	Index uint64
+
+	mu        sync.Mutex
+	key       Key
+	skPath    string
+	epochPath string
+	lastEpoch uint64
+	signed    bool // whether lastEpoch is set
+	warned    bool // whether the expiry warning was logged
+}
+
+// Load loads validator index's keypair from the keys directory, along with
+// the last epoch it signed, if any.
+func Load(dir string, index uint64) (*ManagedKey, error) {
+	pkPath := filepath.Join(dir, fmt.Sprintf("validator_%d_pk.ssz", index))
+	skPath := filepath.Join(dir, fmt.Sprintf("validator_%d_sk.ssz", index))
+	kp, err := leansig.LoadKeypair(pkPath, skPath)
+	if err != nil {
+		return nil, err
+	}
+	epochPath := filepath.Join(dir, fmt.Sprintf("validator_%d_last_epoch", index))
+	return New(kp, index, skPath, epochPath)
+}
+
+// New wraps key. The secret key is written back to skPath when its prepared
+// window advances; the last signed epoch is kept in epochPath.
+func New(key Key, index uint64, skPath, epochPath string) (*ManagedKey, error) {
+	k := &ManagedKey{Index: index, key: key, skPath: skPath, epochPath: epochPath}
+	data, err := os.ReadFile(epochPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("read last epoch: %w", err)
+	default:
+		k.lastEpoch, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse last epoch in %s: %w", epochPath, err)
+		}
+		k.signed = true
+	}
+	return k, nil
+}
+
+// LastEpoch returns the last epoch signed, and whether there is one.
+func (k *ManagedKey) LastEpoch() (uint64, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lastEpoch, k.signed
+}
+
+// Sign signs message at epoch. The epoch is recorded on disk before the
+// signature is produced, so a crash can never lead to it being reused.
+func (k *ManagedKey) Sign(epoch uint32, message [leansig.MessageLength]byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e := uint64(epoch)
+	if k.signed && e <= k.lastEpoch {
+		return nil, fmt.Errorf("%w: epoch %d, last signed %d", ErrEpochUsed, e, k.lastEpoch)
+	}
+	if start, end := k.key.ActivationStart(), k.key.ActivationEnd(); e < start || e >= end {
+		return nil, fmt.Errorf("epoch %d outside key activation [%d, %d)", e, start, end)
+	}
+	// Normally Maintain has prepared the epoch already; this catches up if it has not run.
+	if err := k.prepareLocked(e); err != nil {
+		return nil, err
+	}
+	if start, end := k.key.PreparedStart(), k.key.PreparedEnd(); e < start || e >= end {
+		return nil, fmt.Errorf("epoch %d outside prepared window [%d, %d)", e, start, end)
+	}
+
+	if err := writeFileAtomic(k.epochPath, []byte(strconv.FormatUint(e, 10)), 0600); err != nil {
+		return nil, fmt.Errorf("persist last epoch: %w", err)
+	}
+	k.lastEpoch, k.signed = e, true
+	return k.key.Sign(epoch, message)
+}
+
+// Maintain advances the prepared window so that it reaches at least half a
+// window beyond currentEpoch, and reports how many active epochs are left.
+// It is meant to be called once per slot, off the signing path.
+func (k *ManagedKey) Maintain(currentEpoch uint64) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	lookahead := (k.key.PreparedEnd() - k.key.PreparedStart()) / 2
+	if err := k.prepareLocked(currentEpoch + lookahead); err != nil {
+		return err
+	}
+
+	start, end := k.key.ActivationStart(), k.key.ActivationEnd()
+	remaining := uint64(0)
+	if currentEpoch < end {
+		remaining = end - max(currentEpoch, start)
+	}
+	metrics.XMSSKeyEpochsRemaining.WithLabelValues(strconv.FormatUint(k.Index, 10)).Set(float64(remaining))
+
+	switch {
+	case remaining == 0:
+		if !k.warned {
+			log.Error("xmss key expired", "validator", k.Index, "activation_end", end)
+			k.warned = true
+		}
+	case remaining < (end-start)/expiryWarningFraction:
+		if !k.warned {
+			log.Warn("xmss key nearing end of activation",
+				"validator", k.Index,
+				"epochs_remaining", remaining,
+				"activation_end", end,
+			)
+			k.warned = true
+		}
+	}
+	return nil
+}
+
+// prepareLocked advances the prepared window until it covers epoch or the
+// end of activation, and persists the secret key if it moved.
+func (k *ManagedKey) prepareLocked(epoch uint64) error {
+	advanced := false
+	for epoch >= k.key.PreparedEnd() && k.key.PreparedEnd() < k.key.ActivationEnd() {
+		prevEnd := k.key.PreparedEnd()
+		if err := k.key.AdvancePreparation(); err != nil {
+			return err
+		}
+		if k.key.PreparedEnd() <= prevEnd {
+			return fmt.Errorf("prepared window did not advance past %d", prevEnd)
+		}
+		advanced = true
+	}
+	if !advanced {
+		return nil
+	}
+
+	sk, err := k.key.SecretKeyBytes()
+	if err != nil {
+		return fmt.Errorf("serialize secret key: %w", err)
+	}
+	if err := writeFileAtomic(k.skPath, sk, 0600); err != nil {
+		return fmt.Errorf("persist secret key: %w", err)
+	}
+	log.Debug("advanced xmss prepared window",
+		"validator", k.Index,
+		"prepared_start", k.key.PreparedStart(),
+		"prepared_end", k.key.PreparedEnd(),
+	)
+	return nil
+}
+
+// writeFileAtomic replaces path with data, so a crash leaves either the old
+// or the new contents and never a partial file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,115 @@
+package keymanager_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/geanlabs/gean/xmss/keymanager"
+	"github.com/geanlabs/gean/xmss/leansig"
+)
+
+// fakeKey has a prepared window of 16 epochs that advances by 8.
+type fakeKey struct {
+	activationEnd uint64
+	preparedStart uint64
+	signs         int
+}
+
+func (k *fakeKey) Sign(uint32, [leansig.MessageLength]byte) ([]byte, error) {
+	k.signs++
+	return []byte{1}, nil
+}
+func (k *fakeKey) ActivationStart() uint64 { return 0 }
+func (k *fakeKey) ActivationEnd() uint64   { return k.activationEnd }
+func (k *fakeKey) PreparedStart() uint64   { return k.preparedStart }
+func (k *fakeKey) PreparedEnd() uint64     { return k.preparedStart + 16 }
+func (k *fakeKey) AdvancePreparation() error {
+	k.preparedStart += 8
+	return nil
+}
+func (k *fakeKey) SecretKeyBytes() ([]byte, error) {
+	return []byte(strconv.FormatUint(k.preparedStart, 10)), nil
+}
+
+func newTestKey(t *testing.T, dir string, key *fakeKey) *keymanager.ManagedKey {
+	t.Helper()
+	k, err := keymanager.New(key, 0, filepath.Join(dir, "sk"), filepath.Join(dir, "epoch"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return k
+}
+
+func TestSignRefusesEpochReuseAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	key := &fakeKey{activationEnd: 64}
+
+	k := newTestKey(t, dir, key)
+	if _, err := k.Sign(5, [32]byte{}); err != nil {
+		t.Fatalf("Sign(5): %v", err)
+	}
+	if _, err := k.Sign(5, [32]byte{}); !errors.Is(err, keymanager.ErrEpochUsed) {
+		t.Fatalf("Sign(5) again: err = %v, want ErrEpochUsed", err)
+	}
+
+	k = newTestKey(t, dir, key)
+	if _, err := k.Sign(4, [32]byte{}); !errors.Is(err, keymanager.ErrEpochUsed) {
+		t.Fatalf("Sign(4) after restart: err = %v, want ErrEpochUsed", err)
+	}
+	if _, err := k.Sign(6, [32]byte{}); err != nil {
+		t.Fatalf("Sign(6) after restart: %v", err)
+	}
+	if key.signs != 2 {
+		t.Fatalf("signs = %d, want 2", key.signs)
+	}
+}
+
+func TestSignRefusesOutsideActivation(t *testing.T) {
+	k := newTestKey(t, t.TempDir(), &fakeKey{activationEnd: 64})
+	if _, err := k.Sign(64, [32]byte{}); err == nil {
+		t.Fatal("expected error signing past activation end")
+	}
+}
+
+func TestMaintainAdvancesAndPersistsSecretKey(t *testing.T) {
+	dir := t.TempDir()
+	key := &fakeKey{activationEnd: 64}
+	k := newTestKey(t, dir, key)
+
+	// Window [0, 16): epoch 10 plus half a window is past the end.
+	if err := k.Maintain(10); err != nil {
+		t.Fatalf("Maintain: %v", err)
+	}
+	if key.PreparedEnd() <= 18 {
+		t.Fatalf("prepared end = %d, want > 18", key.PreparedEnd())
+	}
+	sk, err := os.ReadFile(filepath.Join(dir, "sk"))
+	if err != nil {
+		t.Fatalf("read secret key: %v", err)
+	}
+	if got := string(sk); got != strconv.FormatUint(key.preparedStart, 10) {
+		t.Fatalf("persisted secret key = %q, want prepared start %d", got, key.preparedStart)
+	}
+
+	// Never advances beyond the end of activation.
+	if err := k.Maintain(1000); err != nil {
+		t.Fatalf("Maintain: %v", err)
+	}
+	if key.PreparedEnd() > 64+8 {
+		t.Fatalf("prepared end = %d, advanced past activation end", key.PreparedEnd())
+	}
+}
+
+func TestSignPreparesWindowWhenBehind(t *testing.T) {
+	key := &fakeKey{activationEnd: 64}
+	k := newTestKey(t, t.TempDir(), key)
+	if _, err := k.Sign(40, [32]byte{}); err != nil {
+		t.Fatalf("Sign(40): %v", err)
+	}
+	if key.PreparedStart() > 40 || key.PreparedEnd() <= 40 {
+		t.Fatalf("prepared window [%d, %d) does not cover 40", key.PreparedStart(), key.PreparedEnd())
+	}
+}